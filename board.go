@@ -0,0 +1,256 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/skipperoo/tuido/internal/core"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Board layout, in terminal cells
+const (
+	boardCardHeight  = 4 // Two lines of content plus the border
+	boardMinColWidth = 14
+	boardNotesMin    = 20
+	boardNotesMax    = 40
+)
+
+// boardColumns returns the todos of each workflow state, in workflow order.
+// Cards keep their order in the data file, so J/K reordering persists.
+func (m model) boardColumns() [][]core.Entry {
+	entries := core.LiveEntries(m.listEntries())
+	if m.tagFilter != "" {
+		entries = core.FilterByTag(entries, m.tagFilter)
+	}
+	columns := make([][]core.Entry, len(m.workflow.States))
+	for i, state := range m.workflow.States {
+		columns[i] = core.FilterByStatus(entries, state.Name, m.workflow)
+	}
+	return columns
+}
+
+// clampBoardCursor keeps the focused card inside the board after it changed
+func (m *model) clampBoardCursor() {
+	columns := m.boardColumns()
+	m.boardCol = max(0, min(m.boardCol, len(columns)-1))
+	if len(columns) == 0 {
+		m.boardRow = 0
+		return
+	}
+	m.boardRow = max(0, min(m.boardRow, len(columns[m.boardCol])-1))
+}
+
+// focusBoardCard moves the cursor to the card with the given ID
+func (m *model) focusBoardCard(id string) {
+	for c, column := range m.boardColumns() {
+		for r, e := range column {
+			if e.ID == id {
+				m.boardCol, m.boardRow = c, r
+				return
+			}
+		}
+	}
+	m.clampBoardCursor()
+}
+
+func (m model) updateBoard(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	m.msg = ""
+
+	columns := m.boardColumns()
+	var card core.Entry
+	hasCard := m.boardRow < len(columns[m.boardCol])
+	if hasCard {
+		card = columns[m.boardCol][m.boardRow]
+	}
+
+	switch key.String() {
+	case "esc", "q":
+		m.state = stateViewMain
+		m.updateViewport()
+		return m, nil
+	case "ctrl+z", "ctrl+y":
+		m.undo(key.String() == "ctrl+y")
+		m.clampBoardCursor()
+		return m, nil
+	case "left":
+		if m.boardCol > 0 {
+			m.boardCol--
+			m.clampBoardCursor()
+		}
+	case "right":
+		if m.boardCol < len(columns)-1 {
+			m.boardCol++
+			m.clampBoardCursor()
+		}
+	case "up", "k":
+		if m.boardRow > 0 {
+			m.boardRow--
+		}
+	case "down", "j":
+		if m.boardRow < len(columns[m.boardCol])-1 {
+			m.boardRow++
+		}
+	case "h", "l":
+		// Move the card to the neighbouring state
+		target := m.boardCol - 1
+		if key.String() == "l" {
+			target = m.boardCol + 1
+		}
+		if !hasCard || target < 0 || target >= len(columns) {
+			break
+		}
+		state := m.workflow.States[target]
+		warning := ""
+		if state.Terminal {
+			warning = m.blockedWarning(card)
+		}
+		entries, err := core.SetStatus(m.entries, card.ID, state.Name, m.author, m.workflow)
+		if err != nil {
+			m.msg = err.Error()
+			break
+		}
+		m.entries = entries
+		m.save()
+		if m.msg == "" {
+			m.msg = warning
+		}
+		m.focusBoardCard(card.ID)
+	case "K", "J":
+		// Swap with the card above or below in the same column
+		neighbour := m.boardRow - 1
+		if key.String() == "J" {
+			neighbour = m.boardRow + 1
+		}
+		if !hasCard || neighbour < 0 || neighbour >= len(columns[m.boardCol]) {
+			break
+		}
+		m.entries = core.SwapEntries(m.entries, card.ID, columns[m.boardCol][neighbour].ID)
+		m.save()
+		m.focusBoardCard(card.ID)
+	}
+	return m, nil
+}
+
+func (m model) viewBoard() string {
+	width, height := m.width, m.height
+	if width == 0 {
+		width, height = 80, 24
+	}
+	columns := m.boardColumns()
+
+	notesWidth := max(boardNotesMin, min(boardNotesMax, width/4))
+	colWidth := max(boardMinColWidth, (width-notesWidth)/max(1, len(columns)))
+	// Header line and help lines around the board, plus the column title
+	bodyHeight := max(boardCardHeight, height-5)
+	fits := max(1, (bodyHeight-2)/boardCardHeight)
+
+	var rendered []string
+	for c, column := range columns {
+		state := m.workflow.States[c]
+		title := fmt.Sprintf("%s (%d)", strings.ToUpper(state.Name), len(column))
+		if c == m.boardCol {
+			title = cMagenta.Bold(true).Render(title)
+		} else {
+			title = cBlue.Render(title)
+		}
+
+		// Scroll the focused column so its focused card stays visible
+		start := 0
+		if c == m.boardCol && m.boardRow >= fits {
+			start = m.boardRow - fits + 1
+		}
+		end := min(len(column), start+fits)
+
+		lines := []string{title}
+		if start > 0 {
+			lines[0] += cGray.Render(fmt.Sprintf(" ↑%d", start))
+		}
+		for r := start; r < end; r++ {
+			lines = append(lines, m.renderCard(column[r], colWidth-1, c == m.boardCol && r == m.boardRow))
+		}
+		if end < len(column) {
+			lines = append(lines, cGray.Render(fmt.Sprintf("↓ %d more", len(column)-end)))
+		}
+		rendered = append(rendered, lipgloss.NewStyle().Width(colWidth).Render(strings.Join(lines, "\n")))
+	}
+	rendered = append(rendered, m.renderNotesPanel(notesWidth, bodyHeight))
+
+	header := cMagenta.Render("Board") + cGray.Render(" | Author: ") + cBlue.Render(m.author)
+	if m.tagFilter != "" {
+		header += cGray.Render(" | Filter: ") + cBlue.Render("#"+m.tagFilter)
+	}
+	help := cGray.Render("←/→ column | ↑/↓ card | h/l move card | K/J reorder | ^Z undo | Esc back")
+	if m.msg != "" {
+		help = cCyan.Render(m.msg) + "\n" + help
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		header,
+		"",
+		lipgloss.JoinHorizontal(lipgloss.Top, rendered...),
+		"",
+		help,
+	)
+}
+
+// renderCard draws a todo as a bordered card of a fixed height
+func (m model) renderCard(e core.Entry, width int, focused bool) string {
+	inner := max(1, width-2)
+	meta := m.shortIDs[e.ID] + renderPriority(e.Priority) + renderDue(e, time.Now())
+	text := truncate(e.Text, inner)
+	if core.IsBlocked(m.entries, e) {
+		text = cGray.Render(truncate("⧗ "+e.Text, inner))
+	}
+
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("8")).
+		Width(inner).
+		MaxWidth(width)
+	if focused {
+		style = style.BorderForeground(lipgloss.Color("5"))
+	}
+	return style.Render(lipgloss.NewStyle().MaxWidth(inner).Render(meta) + "\n" + text)
+}
+
+// renderNotesPanel lists the most recent notes that fit in the side panel
+func (m model) renderNotesPanel(width, height int) string {
+	var notes []core.Entry
+	for _, e := range core.LiveEntries(m.listEntries()) {
+		if e.Type == core.TypeNote && (m.tagFilter == "" || core.HasTag(e, m.tagFilter)) {
+			notes = append(notes, e)
+		}
+	}
+
+	lines := []string{cBlue.Render(fmt.Sprintf("NOTES (%d)", len(notes)))}
+	shown := min(len(notes), height-1)
+	for _, n := range notes[len(notes)-shown:] {
+		lines = append(lines, cGray.Render(m.shortIDs[n.ID])+" "+truncate(n.Text, width-len(m.shortIDs[n.ID])-3))
+	}
+	return lipgloss.NewStyle().
+		Width(width).
+		Height(height).
+		PaddingLeft(1).
+		Border(lipgloss.NormalBorder(), false, false, false, true).
+		BorderForeground(lipgloss.Color("8")).
+		Render(strings.Join(lines, "\n"))
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if n < 1 {
+		return ""
+	}
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
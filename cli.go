@@ -0,0 +1,1016 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/skipperoo/tuido/internal/core"
+)
+
+// Exit codes for headless commands
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+const cliUsage = `Usage: tuido [--file path] [command] [arguments]
+
+Run without a command to start the interactive TUI.
+An <id> is a full entry ID or any unique prefix of it, as shown by list.
+The data file is --file, else $TUIDO_FILE, else the nearest .tuido in the
+current directory or its parents up to the git root or your home directory.
+
+Commands:
+  add [-todo] [-author name] [-list name] <text>
+                                      Add a note (or a todo with -todo)
+  todo [-author name] [-parent id] [-list name] <text>
+                                      Add a todo, optionally as a subtask
+  done [-r] <id>...                   Mark todos (and with -r, their subtasks) as completed
+  undone <id>...                      Revert completed todos to active
+  rm <id>...                          Move entries to the trash
+  trash                               List the entries in the trash
+  restore <id>...                     Take entries out of the trash
+  purge [-all] [id...]                Permanently delete entries in the trash
+  edit <id> <text>                    Replace the text and #tags of an entry
+  priority <id> <level>               Set a todo's priority (urgent, high, medium, low, none)
+  due <id> <when>                     Set a todo's due date (friday, +3d, 2026-11-01, none)
+  assign <id> [@name...]              Replace an entry's assignees (none to clear)
+  status <id> <state>                 Move a todo to another workflow state
+  block <id> <blocker-id>...          Mark a todo as blocked until the blockers are done
+  unblock <id> [blocker-id...]        Remove some or all of a todo's blockers
+  lists                               Show the named lists and how many entries they hold
+  move <list> <id>...                 Move entries and their subtasks to another list
+  graph [-format dot|mermaid]         Print the dependency graph; fails on cycles
+  log <id>                            Show who edited, completed or moved an entry, and when
+  list [flags] [query]                List active entries, or those matching a query (alias: query)
+      -all | -todos | -done             Select all, open todos or completed todos
+      -upcoming                         Open todos with a due date, soonest first
+      -mine                             Open todos assigned to you
+      -status state                     Todos in this workflow state (completed ones too)
+      -assignee name                    Only entries assigned to name
+      -filter text                      Only entries matching text, author or tags
+      -tag name                         Only entries with this tag
+      -list name                        Only entries on this list
+      -archived                         Include archived entries
+      -format text|json|yaml|ndjson     Output format
+      query: type:todo author:alice tag:api created:>2026-09-01 -done in:archive ...
+             (see README; with a query, completed todos are included)
+  archive [-days n]                   Move todos completed over n days ago (default 30) to .tuido.archive
+  export [-list name] [-o file]       Print the Markdown export of one list or all of them
+  dashboard                           Open todos of every project tuido has run in, completable in place
+  undo                                Revert the last change (by the TUI or a command)
+  redo                                Re-apply the last undone change
+  recover                             Restore the data file from its backup
+  merge-driver <base> <ours> <theirs> Git merge driver for .tuido files
+  install-merge-driver [-global]      Register the merge driver with git
+  help                                Show this help
+`
+
+// usageError signals a malformed invocation (exit code 2)
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+// cli holds the state shared by the headless commands
+type cli struct {
+	stdout   io.Writer
+	stderr   io.Writer
+	fileFlag string // Data file given with --file, if any
+	filePath string
+}
+
+// runCLI executes a headless command and returns the process exit code
+func runCLI(args []string, fileFlag string, stdout, stderr io.Writer) int {
+	c := &cli{stdout: stdout, stderr: stderr, fileFlag: fileFlag}
+
+	name, rest := args[0], args[1:]
+	var err error
+	switch name {
+	case "add":
+		err = c.runAdd(rest, core.TypeNote)
+	case "todo":
+		err = c.runAdd(rest, core.TypeTodo)
+	case "done":
+		err = c.runMark(rest, true)
+	case "undone":
+		err = c.runMark(rest, false)
+	case "rm":
+		err = c.runRemove(rest)
+	case "trash":
+		err = c.runTrash(rest)
+	case "restore":
+		err = c.runRestore(rest)
+	case "purge":
+		err = c.runPurge(rest)
+	case "edit":
+		err = c.runEdit(rest)
+	case "priority":
+		err = c.runPriority(rest)
+	case "due":
+		err = c.runDue(rest)
+	case "assign":
+		err = c.runAssign(rest)
+	case "status":
+		err = c.runStatus(rest)
+	case "block":
+		err = c.runBlock(rest)
+	case "unblock":
+		err = c.runUnblock(rest)
+	case "lists":
+		err = c.runLists(rest)
+	case "move":
+		err = c.runMove(rest)
+	case "graph":
+		err = c.runGraph(rest)
+	case "log":
+		err = c.runLog(rest)
+	case "list", "ls", "query":
+		err = c.runList(rest)
+	case "archive":
+		err = c.runArchive(rest)
+	case "export":
+		err = c.runExport(rest)
+	case "dashboard":
+		err = c.runDashboard(rest)
+	case "undo":
+		err = c.runUndo(rest, false)
+	case "redo":
+		err = c.runUndo(rest, true)
+	case "recover":
+		err = c.runRecover(rest)
+	case "merge-driver":
+		err = c.runMergeDriver(rest)
+	case "install-merge-driver":
+		err = c.runInstallMergeDriver(rest)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, cliUsage)
+		return exitOK
+	default:
+		err = usageError{fmt.Sprintf("unknown command %q", name)}
+	}
+
+	if err == nil {
+		return exitOK
+	}
+	var ue usageError
+	if errors.As(err, &ue) {
+		fmt.Fprintf(stderr, "tuido %s: %v\n\n%s", name, err, cliUsage)
+		return exitUsage
+	}
+	fmt.Fprintf(stderr, "tuido %s: %v\n", name, err)
+	return exitError
+}
+
+// newFlagSet creates a flag set that reports errors instead of exiting
+func (c *cli) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// parseFlags parses args, turning flag errors into usage errors
+func (c *cli) parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return usageError{err.Error()}
+	}
+	return nil
+}
+
+// dataFile resolves the data file once per invocation
+func (c *cli) dataFile() (string, error) {
+	if c.filePath == "" {
+		path, err := resolveDataFile(c.fileFlag)
+		if err != nil {
+			return "", err
+		}
+		c.filePath = path
+	}
+	return c.filePath, nil
+}
+
+// config loads the project's config file
+func (c *cli) config() (core.ProjectConfig, error) {
+	path, err := c.dataFile()
+	if err != nil {
+		return core.ProjectConfig{}, err
+	}
+	return core.LoadProjectConfig(path)
+}
+
+// workflow loads the project's workflow from its config file
+func (c *cli) workflow() (core.Workflow, error) {
+	cfg, err := c.config()
+	return cfg.Workflow, err
+}
+
+func (c *cli) load() ([]core.Entry, error) {
+	path, err := c.dataFile()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := core.LoadEntries(path)
+	return entries, withRecoverHint(err)
+}
+
+// update runs a load-modify-save cycle under the file lock. Output written
+// to out is only printed once the change has been saved.
+func (c *cli) update(fn func(entries []core.Entry, out io.Writer) ([]core.Entry, error)) error {
+	path, err := c.dataFile()
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	err = core.UpdateEntries(path, func(entries []core.Entry) ([]core.Entry, error) {
+		return fn(entries, &out)
+	})
+	if errors.Is(err, core.ErrHistory) {
+		fmt.Fprintf(c.stderr, "warning: %v\n", err)
+	} else if err != nil {
+		return withRecoverHint(err)
+	}
+	// Best effort: the dashboard just misses the project if this fails
+	core.RegisterProject(path)
+	if cfg, err := c.config(); err == nil {
+		if _, err := core.PurgeExpiredTrash(path, cfg.TrashDays); err != nil {
+			fmt.Fprintf(c.stderr, "warning: could not empty the trash: %v\n", err)
+		}
+	}
+	_, err = c.stdout.Write(out.Bytes())
+	return err
+}
+
+func withRecoverHint(err error) error {
+	if errors.Is(err, core.ErrCorrupt) {
+		return fmt.Errorf("%w (run 'tuido recover' to restore the backup)", err)
+	}
+	return err
+}
+
+// listFlag validates the value of a -list flag; empty means no list was given
+func listFlag(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	name, ok := core.NormalizeList(value)
+	if !ok {
+		return "", usageError{fmt.Sprintf("invalid list name %q", value)}
+	}
+	return name, nil
+}
+
+// lookup resolves a full or short ID to its entry
+func (c *cli) lookup(entries []core.Entry, ref string) (core.Entry, error) {
+	return core.ResolveRef(entries, ref)
+}
+
+func (c *cli) runAdd(args []string, entryType core.EntryType) error {
+	fs := c.newFlagSet("add")
+	author := fs.String("author", "", "author of the entry")
+	todo := fs.Bool("todo", false, "add a todo instead of a note")
+	parent := fs.String("parent", "", "add the todo as a subtask of this entry")
+	listName := fs.String("list", "", "add the entry to this list")
+	if err := c.parseFlags(fs, args); err != nil {
+		return err
+	}
+	list, err := listFlag(*listName)
+	if err != nil {
+		return err
+	}
+	if *todo || *parent != "" {
+		entryType = core.TypeTodo
+	}
+
+	text := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if text == "" {
+		return usageError{"missing text"}
+	}
+	if *author == "" {
+		*author = resolveAuthor()
+	}
+
+	return c.update(func(entries []core.Entry, out io.Writer) ([]core.Entry, error) {
+		entries = core.AddEntry(entries, text, *author, entryType)
+		if list != "" {
+			entries = core.MoveToList(entries, map[string]struct{}{entries[len(entries)-1].ID: {}}, list)
+		}
+		if *parent != "" {
+			p, err := c.lookup(entries, *parent)
+			if err != nil {
+				return nil, err
+			}
+			// Subtasks are always on their parent's list
+			if list != "" && list != core.ListOf(p) {
+				return nil, fmt.Errorf("the parent %s is on list %s, not %s", core.ShortID(entries, p.ID), core.ListOf(p), list)
+			}
+			if entries, err = core.SetParent(entries, entries[len(entries)-1].ID, p.ID); err != nil {
+				return nil, err
+			}
+		}
+		// Print the short ID so scripts can reference the entry later
+		fmt.Fprintln(out, core.ShortID(entries, entries[len(entries)-1].ID))
+		return entries, nil
+	})
+}
+
+func (c *cli) runMark(args []string, done bool) error {
+	fs := c.newFlagSet("done")
+	recursive := false
+	if done {
+		fs.BoolVar(&recursive, "r", false, "also complete open subtasks")
+	}
+	if err := c.parseFlags(fs, args); err != nil {
+		return err
+	}
+	args = fs.Args()
+	if len(args) == 0 {
+		return usageError{"missing entry id"}
+	}
+
+	w, err := c.workflow()
+	if err != nil {
+		return err
+	}
+	return c.update(func(entries []core.Entry, out io.Writer) ([]core.Entry, error) {
+		short := core.ShortIDs(entries)
+		for _, ref := range args {
+			e, err := c.lookup(entries, ref)
+			if err != nil {
+				return nil, err
+			}
+			if e.Type != core.TypeTodo {
+				return nil, fmt.Errorf("entry %s is a %s, not a todo", short[e.ID], e.Type)
+			}
+			if done {
+				if entries, err = core.CompleteTodo(entries, e.ID, resolveAuthor(), w); err != nil {
+					return nil, fmt.Errorf("cannot complete %s: %w", short[e.ID], err)
+				}
+				fmt.Fprintf(out, "done %s: %s\n", short[e.ID], e.Text)
+				if blockers := core.OpenBlockers(entries, e); len(blockers) > 0 {
+					fmt.Fprintf(c.stderr, "warning: %s is blocked by open todos: %s\n", short[e.ID], shortRefs(short, blockers))
+				}
+				open := core.GetOpenSubtasks(entries, e.ID)
+				if recursive {
+					for _, sub := range open {
+						if entries, err = core.CompleteTodo(entries, sub.ID, resolveAuthor(), w); err != nil {
+							return nil, fmt.Errorf("cannot complete %s: %w", short[sub.ID], err)
+						}
+						fmt.Fprintf(out, "done %s: %s\n", short[sub.ID], sub.Text)
+					}
+				} else if len(open) > 0 {
+					fmt.Fprintf(c.stderr, "warning: %s still has %d open subtasks (use done -r to complete them)\n", short[e.ID], len(open))
+				}
+			} else {
+				if entries, err = core.ReopenTodo(entries, e.ID, resolveAuthor(), w); err != nil {
+					return nil, fmt.Errorf("cannot reopen %s: %w", short[e.ID], err)
+				}
+				fmt.Fprintf(out, "undone %s: %s\n", short[e.ID], e.Text)
+			}
+		}
+		return entries, nil
+	})
+}
+
+func (c *cli) runRemove(args []string) error {
+	if len(args) == 0 {
+		return usageError{"missing entry id"}
+	}
+
+	return c.update(func(entries []core.Entry, out io.Writer) ([]core.Entry, error) {
+		short := core.ShortIDs(entries)
+		ids := make(map[string]struct{})
+		for _, ref := range args {
+			e, err := c.lookup(entries, ref)
+			if err != nil {
+				return nil, err
+			}
+			ids[e.ID] = struct{}{}
+			fmt.Fprintf(out, "trashed %s: %s\n", short[e.ID], e.Text)
+		}
+		return core.TrashEntries(entries, ids, resolveAuthor()), nil
+	})
+}
+
+func (c *cli) runTrash(args []string) error {
+	if len(args) > 0 {
+		return usageError{fmt.Sprintf("unexpected argument %q", args[0])}
+	}
+	entries, err := c.load()
+	if err != nil {
+		return err
+	}
+	short := core.ShortIDs(entries)
+	for _, e := range core.GetTrashedEntries(entries) {
+		fmt.Fprintf(c.stdout, "%s  %s  %s\n", short[e.ID], e.DeletedAt.Local().Format("2006-01-02 15:04"), e.Text)
+	}
+	return nil
+}
+
+func (c *cli) runRestore(args []string) error {
+	if len(args) == 0 {
+		return usageError{"missing entry id"}
+	}
+
+	return c.update(func(entries []core.Entry, out io.Writer) ([]core.Entry, error) {
+		short := core.ShortIDs(entries)
+		ids := make(map[string]struct{})
+		for _, ref := range args {
+			e, err := c.lookup(entries, ref)
+			if err != nil {
+				return nil, err
+			}
+			if !e.Trashed() {
+				return nil, fmt.Errorf("%s is not in the trash", short[e.ID])
+			}
+			ids[e.ID] = struct{}{}
+			fmt.Fprintf(out, "restored %s: %s\n", short[e.ID], e.Text)
+		}
+		return core.RestoreEntries(entries, ids, resolveAuthor()), nil
+	})
+}
+
+func (c *cli) runPurge(args []string) error {
+	fs := c.newFlagSet("purge")
+	all := fs.Bool("all", false, "empty the whole trash")
+	if err := c.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *all == (fs.NArg() > 0) {
+		return usageError{"pass entry ids or -all"}
+	}
+
+	return c.update(func(entries []core.Entry, out io.Writer) ([]core.Entry, error) {
+		short := core.ShortIDs(entries)
+		var purged []core.Entry
+		if *all {
+			purged = core.GetTrashedEntries(entries)
+		}
+		for _, ref := range fs.Args() {
+			e, err := c.lookup(entries, ref)
+			if err != nil {
+				return nil, err
+			}
+			if !e.Trashed() {
+				return nil, fmt.Errorf("%s is not in the trash (rm it first)", short[e.ID])
+			}
+			purged = append(purged, e)
+		}
+		ids := make(map[string]struct{})
+		for _, e := range purged {
+			ids[e.ID] = struct{}{}
+			fmt.Fprintf(out, "purged %s: %s\n", short[e.ID], e.Text)
+		}
+		return core.RemoveEntries(entries, ids), nil
+	})
+}
+
+func (c *cli) runEdit(args []string) error {
+	if len(args) < 2 {
+		return usageError{"usage: edit <id> <text>"}
+	}
+	text := strings.TrimSpace(strings.Join(args[1:], " "))
+	if text == "" {
+		return usageError{"missing text"}
+	}
+
+	return c.update(func(entries []core.Entry, out io.Writer) ([]core.Entry, error) {
+		e, err := c.lookup(entries, args[0])
+		if err != nil {
+			return nil, err
+		}
+		entries = core.EditEntry(entries, e.ID, text, resolveAuthor())
+		fmt.Fprintf(out, "edited %s: %s\n", core.ShortID(entries, e.ID), text)
+		return entries, nil
+	})
+}
+
+func (c *cli) runPriority(args []string) error {
+	if len(args) != 2 {
+		return usageError{"usage: priority <id> <level>"}
+	}
+	level, ok := core.ParsePriority(args[1])
+	if !ok {
+		return usageError{fmt.Sprintf("unknown priority %q", args[1])}
+	}
+
+	return c.update(func(entries []core.Entry, out io.Writer) ([]core.Entry, error) {
+		e, err := c.lookup(entries, args[0])
+		if err != nil {
+			return nil, err
+		}
+		if e.Type != core.TypeTodo {
+			return nil, fmt.Errorf("entry %s is a %s, not a todo", core.ShortID(entries, e.ID), e.Type)
+		}
+		entries = core.SetPriority(entries, e.ID, level)
+		name := string(level)
+		if level == core.PriorityNone {
+			name = "none"
+		}
+		fmt.Fprintf(out, "priority %s %s: %s\n", core.ShortID(entries, e.ID), name, e.Text)
+		return entries, nil
+	})
+}
+
+func (c *cli) runDue(args []string) error {
+	if len(args) < 2 {
+		return usageError{"usage: due <id> <when>"}
+	}
+	var due *time.Time
+	if expr := strings.Join(args[1:], " "); expr != "none" && expr != "clear" {
+		t, err := core.ParseDue(expr, time.Now())
+		if err != nil {
+			return usageError{err.Error()}
+		}
+		due = &t
+	}
+
+	return c.update(func(entries []core.Entry, out io.Writer) ([]core.Entry, error) {
+		e, err := c.lookup(entries, args[0])
+		if err != nil {
+			return nil, err
+		}
+		if e.Type != core.TypeTodo {
+			return nil, fmt.Errorf("entry %s is a %s, not a todo", core.ShortID(entries, e.ID), e.Type)
+		}
+		entries = core.SetDue(entries, e.ID, due)
+		when := "none"
+		if due != nil {
+			when = core.FormatDue(*due)
+		}
+		fmt.Fprintf(out, "due %s %s: %s\n", core.ShortID(entries, e.ID), when, e.Text)
+		return entries, nil
+	})
+}
+
+func (c *cli) runAssign(args []string) error {
+	if len(args) < 1 {
+		return usageError{"usage: assign <id> [@name...]"}
+	}
+	names := args[1:]
+	if len(names) == 1 && names[0] == "none" {
+		names = nil
+	}
+
+	return c.update(func(entries []core.Entry, out io.Writer) ([]core.Entry, error) {
+		e, err := c.lookup(entries, args[0])
+		if err != nil {
+			return nil, err
+		}
+		entries = core.SetAssignees(entries, e.ID, names)
+		e, _ = core.FindEntry(entries, e.ID)
+		fmt.Fprintf(out, "assigned %s: %s\n", core.ShortID(entries, e.ID), core.FormatPeople(e))
+		return entries, nil
+	})
+}
+
+func (c *cli) runStatus(args []string) error {
+	if len(args) != 2 {
+		return usageError{"usage: status <id> <state>"}
+	}
+	w, err := c.workflow()
+	if err != nil {
+		return err
+	}
+
+	return c.update(func(entries []core.Entry, out io.Writer) ([]core.Entry, error) {
+		e, err := c.lookup(entries, args[0])
+		if err != nil {
+			return nil, err
+		}
+		if entries, err = core.SetStatus(entries, e.ID, args[1], resolveAuthor(), w); err != nil {
+			return nil, err
+		}
+		e, _ = core.FindEntry(entries, e.ID)
+		fmt.Fprintf(out, "%s %s: %s\n", e.Status, core.ShortID(entries, e.ID), e.Text)
+		return entries, nil
+	})
+}
+
+func (c *cli) runBlock(args []string) error {
+	if len(args) < 2 {
+		return usageError{"usage: block <id> <blocker-id>..."}
+	}
+
+	return c.update(func(entries []core.Entry, out io.Writer) ([]core.Entry, error) {
+		short := core.ShortIDs(entries)
+		e, err := c.lookup(entries, args[0])
+		if err != nil {
+			return nil, err
+		}
+		if e.Type != core.TypeTodo {
+			return nil, fmt.Errorf("entry %s is a %s, not a todo", short[e.ID], e.Type)
+		}
+		for _, ref := range args[1:] {
+			b, err := c.lookup(entries, ref)
+			if err != nil {
+				return nil, err
+			}
+			if entries, err = core.AddBlocker(entries, e.ID, b.ID); err != nil {
+				return nil, fmt.Errorf("%s blocked by %s: %w", short[e.ID], short[b.ID], err)
+			}
+			fmt.Fprintf(out, "blocked %s by %s: %s\n", short[e.ID], short[b.ID], b.Text)
+		}
+		return entries, nil
+	})
+}
+
+func (c *cli) runUnblock(args []string) error {
+	if len(args) < 1 {
+		return usageError{"usage: unblock <id> [blocker-id...]"}
+	}
+
+	return c.update(func(entries []core.Entry, out io.Writer) ([]core.Entry, error) {
+		short := core.ShortIDs(entries)
+		e, err := c.lookup(entries, args[0])
+		if err != nil {
+			return nil, err
+		}
+		if len(args) == 1 {
+			entries = core.RemoveBlocker(entries, e.ID, "")
+			fmt.Fprintf(out, "unblocked %s: %s\n", short[e.ID], e.Text)
+			return entries, nil
+		}
+		for _, ref := range args[1:] {
+			b, err := c.lookup(entries, ref)
+			if err != nil {
+				return nil, err
+			}
+			entries = core.RemoveBlocker(entries, e.ID, b.ID)
+			fmt.Fprintf(out, "unblocked %s from %s\n", short[e.ID], short[b.ID])
+		}
+		return entries, nil
+	})
+}
+
+func (c *cli) runLists(args []string) error {
+	if len(args) > 0 {
+		return usageError{fmt.Sprintf("unexpected argument %q", args[0])}
+	}
+	entries, err := c.load()
+	if err != nil {
+		return err
+	}
+	for _, name := range core.ListNames(entries) {
+		on := core.FilterByList(entries, name)
+		todos := len(core.GetActiveTodos(on))
+		notes := len(core.GetActiveItems(on)) - todos
+		fmt.Fprintf(c.stdout, "%s: %d open todos, %d notes\n", name, todos, notes)
+	}
+	return nil
+}
+
+func (c *cli) runMove(args []string) error {
+	if len(args) < 2 {
+		return usageError{"usage: move <list> <id>..."}
+	}
+	list, ok := core.NormalizeList(args[0])
+	if !ok {
+		return usageError{fmt.Sprintf("invalid list name %q", args[0])}
+	}
+
+	return c.update(func(entries []core.Entry, out io.Writer) ([]core.Entry, error) {
+		short := core.ShortIDs(entries)
+		ids := make(map[string]struct{})
+		for _, ref := range args[1:] {
+			e, err := c.lookup(entries, ref)
+			if err != nil {
+				return nil, err
+			}
+			if e.ParentID != "" {
+				return nil, fmt.Errorf("%s is a subtask; move its parent instead", short[e.ID])
+			}
+			ids[e.ID] = struct{}{}
+			fmt.Fprintf(out, "moved %s to %s: %s\n", short[e.ID], list, e.Text)
+		}
+		return core.MoveToList(entries, ids, list), nil
+	})
+}
+
+func (c *cli) runGraph(args []string) error {
+	fs := c.newFlagSet("graph")
+	formatName := fs.String("format", string(core.GraphDOT), "dot or mermaid")
+	if err := c.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usageError{fmt.Sprintf("unexpected argument %q", fs.Arg(0))}
+	}
+	format, err := core.ParseGraphFormat(*formatName)
+	if err != nil {
+		return usageError{err.Error()}
+	}
+
+	entries, err := c.load()
+	if err != nil {
+		return err
+	}
+	entries = core.LiveEntries(entries)
+	if _, err := io.WriteString(c.stdout, core.GenerateGraph(entries, format)); err != nil {
+		return err
+	}
+
+	// The graph is still printed so the cycle can be inspected
+	cycles := core.FindCycles(entries)
+	if len(cycles) == 0 {
+		return nil
+	}
+	short := core.ShortIDs(entries)
+	for _, cycle := range cycles {
+		ids := make([]string, len(cycle))
+		for i, id := range cycle {
+			ids[i] = short[id]
+		}
+		fmt.Fprintf(c.stderr, "cycle: %s\n", strings.Join(ids, " -> "))
+	}
+	return fmt.Errorf("found %d dependency cycle(s)", len(cycles))
+}
+
+// shortRefs lists the short IDs of entries, comma separated
+func shortRefs(short map[string]string, entries []core.Entry) string {
+	refs := make([]string, len(entries))
+	for i, e := range entries {
+		refs[i] = short[e.ID]
+	}
+	return strings.Join(refs, ", ")
+}
+
+func (c *cli) runLog(args []string) error {
+	if len(args) != 1 {
+		return usageError{"usage: log <id>"}
+	}
+	entries, err := c.load()
+	if err != nil {
+		return err
+	}
+	e, err := c.lookup(entries, args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "%s: %s\n", core.ShortID(entries, e.ID), e.Text)
+	timeline := core.Timeline(e)
+	width := 0
+	for _, r := range timeline {
+		width = max(width, len(r.By))
+	}
+	for _, r := range timeline {
+		fmt.Fprintf(c.stdout, "  %s  %-*s  %s\n", r.At.Local().Format("2006-01-02 15:04"), width, r.By, r.Describe())
+	}
+	return nil
+}
+
+func (c *cli) runList(args []string) error {
+	fs := c.newFlagSet("list")
+	all := fs.Bool("all", false, "include completed todos")
+	todos := fs.Bool("todos", false, "only show open todos")
+	done := fs.Bool("done", false, "only show completed todos")
+	upcoming := fs.Bool("upcoming", false, "only show open todos with a due date")
+	tag := fs.String("tag", "", "only show entries with this tag")
+	mine := fs.Bool("mine", false, "only show open todos assigned to you")
+	status := fs.String("status", "", "only show todos in this workflow state")
+	assignee := fs.String("assignee", "", "only show entries assigned to name")
+	filter := fs.String("filter", "", "only show entries matching text or author")
+	archived := fs.Bool("archived", false, "include archived entries")
+	listName := fs.String("list", "", "only show entries on this list")
+	formatName := fs.String("format", string(core.FormatText), "output format")
+	if err := c.parseFlags(fs, args); err != nil {
+		return err
+	}
+	list, err := listFlag(*listName)
+	if err != nil {
+		return err
+	}
+	format, err := core.ParseFormat(*formatName)
+	if err != nil {
+		return usageError{err.Error()}
+	}
+	query, err := core.ParseQuery(strings.Join(fs.Args(), " "))
+	var qe *core.QueryError
+	if errors.As(err, &qe) {
+		return usageError{fmt.Sprintf("invalid query at %v\n\n  %s", err, strings.ReplaceAll(qe.Caret(), "\n", "\n  "))}
+	}
+
+	w, err := c.workflow()
+	if err != nil {
+		return err
+	}
+	entries, err := c.load()
+	if err != nil {
+		return err
+	}
+	if *archived || query.IncludesArchive() {
+		path, _ := c.dataFile()
+		archive, err := core.LoadArchive(path)
+		if err != nil {
+			return err
+		}
+		entries = core.WithArchive(entries, archive)
+	}
+	// Short IDs must be unique across the whole file, not just the listed subset
+	short := core.ShortIDs(entries)
+	loaded := entries
+	entries = core.LiveEntries(entries)
+	if list != "" {
+		entries = core.FilterByList(entries, list)
+	}
+
+	// Filters mirror the core selectors used by the TUI
+	switch {
+	case *status != "":
+		if _, ok := w.State(core.NormalizeStatus(*status)); !ok {
+			return usageError{fmt.Sprintf("unknown status %q", *status)}
+		}
+		entries = core.FilterByStatus(entries, core.NormalizeStatus(*status), w)
+	case *mine:
+		entries = core.GetAssignedTodos(entries, resolveAuthor())
+	case *upcoming:
+		entries = core.GetUpcomingTodos(entries)
+	case *todos:
+		entries = core.GetActiveTodos(entries)
+	case *done:
+		entries = core.GetCompletedTodos(entries)
+	case !*all && query.Empty():
+		// A query decides for itself whether completed todos belong
+		entries = core.GetActiveItems(entries)
+	}
+	entries = query.Filter(entries, core.QueryContext{Entries: loaded, Workflow: w, Now: time.Now()})
+	entries = core.FilterEntries(entries, *filter)
+	if *tag != "" {
+		entries = core.FilterByTag(entries, *tag)
+	}
+	if *assignee != "" {
+		var assigned []core.Entry
+		for _, e := range entries {
+			if core.IsAssignedTo(e, strings.TrimPrefix(*assignee, "@")) {
+				assigned = append(assigned, e)
+			}
+		}
+		entries = assigned
+	}
+
+	if format != core.FormatText {
+		// Spell out derived states so consumers do not need the workflow
+		for i, e := range entries {
+			if e.Type == core.TypeTodo {
+				entries[i].Status = core.StatusOf(e, w)
+			}
+		}
+		return core.EncodeEntries(c.stdout, entries, format)
+	}
+
+	// Subtasks are indented below their parent, except in due date order
+	items := core.FlattenTree(entries, nil)
+	if *upcoming {
+		items = items[:0]
+		for _, e := range entries {
+			items = append(items, core.TreeItem{Entry: e})
+		}
+	}
+	for _, item := range items {
+		e := item.Entry
+		label := "NOTE"
+		if e.Type == core.TypeTodo {
+			label = strings.ToUpper(core.StatusOf(e, w))
+		}
+		if e.Priority != core.PriorityNone {
+			label += " !" + string(e.Priority)
+		}
+		due := ""
+		if e.DueAt != nil {
+			due = " due " + core.FormatDue(*e.DueAt)
+			if core.GetDueState(e, time.Now()) == core.DueOverdue {
+				due += " (overdue)"
+			}
+		}
+		tags := ""
+		for _, t := range e.Tags {
+			tags += " #" + t
+		}
+		blocked := ""
+		if blockers := core.OpenBlockers(loaded, e); e.CompletedAt == nil && len(blockers) > 0 {
+			blocked = " (blocked by " + shortRefs(short, blockers) + ")"
+		}
+		if e.ArchivedAt != nil {
+			blocked += " (archived)"
+		}
+		fmt.Fprintf(c.stdout, "%s%s [%s] %s (%s): %s%s%s%s\n",
+			strings.Repeat("  ", item.Depth), short[e.ID], label, core.FormatPeople(e), e.CreatedAt.Format("2006-01-02 15:04"), e.Text, tags, due, blocked)
+	}
+	return nil
+}
+
+func (c *cli) runArchive(args []string) error {
+	fs := c.newFlagSet("archive")
+	days := fs.Int("days", -1, "archive todos completed more than this many days ago")
+	if err := c.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usageError{fmt.Sprintf("unexpected argument %q", fs.Arg(0))}
+	}
+	cfg, err := c.config()
+	if err != nil {
+		return err
+	}
+	if *days < 0 {
+		*days = cfg.ArchiveDays
+	}
+	path, err := c.dataFile()
+	if err != nil {
+		return err
+	}
+
+	return c.update(func(entries []core.Entry, out io.Writer) ([]core.Entry, error) {
+		short := core.ShortIDs(entries)
+		keep, archived := core.SplitArchivable(entries, time.Now().AddDate(0, 0, -*days))
+		if err := core.WriteArchive(path, archived); err != nil {
+			return nil, err
+		}
+		if len(archived) == 0 {
+			fmt.Fprintf(out, "no todos were completed over %d days ago\n", *days)
+		}
+		for _, e := range archived {
+			fmt.Fprintf(out, "archived %s: %s\n", short[e.ID], e.Text)
+		}
+		return keep, nil
+	})
+}
+
+func (c *cli) runExport(args []string) error {
+	fs := c.newFlagSet("export")
+	output := fs.String("o", "", "write to file instead of stdout")
+	listName := fs.String("list", "", "only export this list")
+	if err := c.parseFlags(fs, args); err != nil {
+		return err
+	}
+	list, err := listFlag(*listName)
+	if err != nil {
+		return err
+	}
+
+	entries, err := c.load()
+	if err != nil {
+		return err
+	}
+
+	content := core.GenerateListsExportMarkdown(entries)
+	if list != "" {
+		content = core.GenerateExportMarkdown(core.FilterByList(entries, list))
+	}
+	if *output == "" {
+		_, err = io.WriteString(c.stdout, content)
+		return err
+	}
+	if err := os.WriteFile(*output, []byte(content), 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "exported to %s\n", *output)
+	return nil
+}
+
+func (c *cli) runUndo(args []string, redo bool) error {
+	if len(args) > 0 {
+		return usageError{fmt.Sprintf("unexpected argument %q", args[0])}
+	}
+	path, err := c.dataFile()
+	if err != nil {
+		return err
+	}
+	op, err := core.UndoEntries(path, redo)
+	if err != nil {
+		return withRecoverHint(err)
+	}
+	verb := "undid"
+	if redo {
+		verb = "redid"
+	}
+	fmt.Fprintf(c.stdout, "%s %s\n", verb, op.Describe())
+	return nil
+}
+
+func (c *cli) runRecover(args []string) error {
+	if len(args) > 0 {
+		return usageError{fmt.Sprintf("unexpected argument %q", args[0])}
+	}
+	path, err := c.dataFile()
+	if err != nil {
+		return err
+	}
+	entries, err := core.RestoreBackup(path)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "restored %d entries from %s\n", len(entries), core.BackupPath(path))
+	return nil
+}
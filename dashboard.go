@@ -0,0 +1,317 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/skipperoo/tuido/internal/core"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// dashboardProject is a registered project as the dashboard shows it
+type dashboardProject struct {
+	name     string // Name of the directory holding the data file
+	path     string
+	workflow core.Workflow
+	entries  []core.Entry
+	shortIDs map[string]string
+	items    []core.TreeItem // Open todos, most important first, subtasks below their parent
+	err      error           // Set if the data file could not be read
+}
+
+// dashboardRow is a todo the dashboard cursor can land on
+type dashboardRow struct {
+	project int
+	entry   core.Entry
+}
+
+// dashboardModel is the TUI of tuido dashboard: the open todos of every
+// registered project, grouped by project
+type dashboardModel struct {
+	projects []dashboardProject
+	rows     []dashboardRow
+	cursor   int
+	offset   int  // First line of the list on screen
+	confirm  bool // Asking whether to complete the open subtasks of the todo under the cursor
+	width    int
+	height   int
+	author   string
+	msg      string
+}
+
+// dashboardDataFiles returns the registered data files that still exist.
+// A project that switched to an event log is listed under its .jsonl file.
+func dashboardDataFiles() ([]string, error) {
+	registered, err := core.LoadProjects()
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, path := range registered {
+		if eventLog := strings.TrimSuffix(path, ".jsonl") + ".jsonl"; fileExists(eventLog) {
+			path = eventLog
+		}
+		if fileExists(path) && !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+func loadDashboardProject(path string) dashboardProject {
+	p := dashboardProject{name: filepath.Base(filepath.Dir(path)), path: path}
+	entries, err := core.LoadEntries(path)
+	if err != nil {
+		p.err = err
+		return p
+	}
+	cfg, err := core.LoadProjectConfig(path)
+	if err != nil {
+		p.err = err
+		return p
+	}
+	p.workflow = cfg.Workflow
+	p.entries = entries
+	p.shortIDs = core.ShortIDs(entries)
+	p.items = core.FlattenTree(core.SortByPriority(core.GetActiveTodos(entries)), nil)
+	return p
+}
+
+func newDashboard() (dashboardModel, error) {
+	m := dashboardModel{author: resolveAuthor()}
+	paths, err := dashboardDataFiles()
+	if err != nil {
+		return m, err
+	}
+	if len(paths) == 0 {
+		return m, fmt.Errorf("no projects yet; run tuido in a project to add it to the dashboard")
+	}
+	for _, path := range paths {
+		m.projects = append(m.projects, loadDashboardProject(path))
+	}
+	m.rebuildRows()
+	return m, nil
+}
+
+// rebuildRows lists the todos of all projects in display order and keeps the
+// cursor in range
+func (m *dashboardModel) rebuildRows() {
+	m.rows = nil
+	for i, p := range m.projects {
+		for _, item := range p.items {
+			m.rows = append(m.rows, dashboardRow{project: i, entry: item.Entry})
+		}
+	}
+	m.cursor = max(0, min(m.cursor, len(m.rows)-1))
+}
+
+// reload re-reads one project after a change, or all of them for -1
+func (m *dashboardModel) reload(project int) {
+	for i, p := range m.projects {
+		if project < 0 || i == project {
+			m.projects[i] = loadDashboardProject(p.path)
+		}
+	}
+	m.rebuildRows()
+}
+
+// openSubtasks returns the open subtasks of the todo under the cursor
+func (m dashboardModel) openSubtasks() []core.Entry {
+	if len(m.rows) == 0 {
+		return nil
+	}
+	row := m.rows[m.cursor]
+	return core.GetOpenSubtasks(m.projects[row.project].entries, row.entry.ID)
+}
+
+// complete marks the todo under the cursor as done in its project's data
+// file, and with subtasks set its open subtasks too. Like /done, it warns if
+// the todo was still blocked.
+func (m *dashboardModel) complete(subtasks bool) {
+	if len(m.rows) == 0 {
+		return
+	}
+	row := m.rows[m.cursor]
+	p := m.projects[row.project]
+	var blockers []core.Entry
+	err := core.UpdateEntries(p.path, func(entries []core.Entry) ([]core.Entry, error) {
+		e, ok := core.FindEntry(entries, row.entry.ID)
+		if !ok {
+			return nil, fmt.Errorf("it was removed in the meantime")
+		}
+		blockers = core.OpenBlockers(entries, e)
+		ids := []string{e.ID}
+		if subtasks {
+			for _, sub := range core.GetOpenSubtasks(entries, e.ID) {
+				ids = append(ids, sub.ID)
+			}
+		}
+		for _, id := range ids {
+			var err error
+			if entries, err = core.CompleteTodo(entries, id, m.author, p.workflow); err != nil {
+				return nil, err
+			}
+		}
+		return entries, nil
+	})
+	switch {
+	case errors.Is(err, core.ErrHistory):
+		m.msg = fmt.Sprintf("Completed, but %v", err)
+	case err != nil:
+		m.msg = fmt.Sprintf("Could not complete %q: %v", row.entry.Text, err)
+	case len(blockers) > 0:
+		m.msg = fmt.Sprintf("Completed %q in %s, but it was still blocked by %s (ctrl+z to undo)", row.entry.Text, p.name, shortRefs(p.shortIDs, blockers))
+	default:
+		m.msg = fmt.Sprintf("Completed %q in %s (ctrl+z to undo)", row.entry.Text, p.name)
+	}
+	m.reload(row.project)
+}
+
+// undo reverts the last change to the project under the cursor
+func (m *dashboardModel) undo() {
+	if len(m.rows) == 0 {
+		return
+	}
+	project := m.rows[m.cursor].project
+	p := m.projects[project]
+	op, err := core.UndoEntries(p.path, false)
+	if err != nil {
+		m.msg = fmt.Sprintf("Could not undo in %s: %v", p.name, err)
+		return
+	}
+	m.msg = fmt.Sprintf("Undid %s in %s", op.Describe(), p.name)
+	m.reload(project)
+}
+
+func (m dashboardModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		m.msg = ""
+		if m.confirm {
+			// Ask before leaving subtasks open under a finished parent
+			switch msg.String() {
+			case "a":
+				m.complete(true)
+			case "p":
+				m.complete(false)
+			case "ctrl+c":
+				return m, tea.Quit
+			}
+			m.confirm = false
+			break
+		}
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			return m, tea.Quit
+		case "up", "k":
+			m.cursor = max(0, m.cursor-1)
+		case "down", "j":
+			m.cursor = max(0, min(m.cursor+1, len(m.rows)-1))
+		case "enter", " ", "x":
+			if len(m.openSubtasks()) > 0 {
+				m.confirm = true
+			} else {
+				m.complete(false)
+			}
+		case "ctrl+z":
+			m.undo()
+		case "r":
+			m.reload(-1)
+		}
+	}
+	m.scroll()
+	return m, nil
+}
+
+// listHeight is the number of list lines that fit on screen. The title, a
+// blank line, the status and the help take four.
+func (m dashboardModel) listHeight() int {
+	return max(1, m.height-4)
+}
+
+// scroll moves the visible part of the list so the cursor stays on screen
+func (m *dashboardModel) scroll() {
+	_, cursorLine := m.renderLines()
+	if cursorLine < m.offset {
+		m.offset = cursorLine
+	}
+	if height := m.listHeight(); cursorLine >= m.offset+height {
+		m.offset = cursorLine - height + 1
+	}
+}
+
+// renderLines returns the list and the line of the cursor within it
+func (m dashboardModel) renderLines() ([]string, int) {
+	var lines []string
+	cursorLine, row := 0, 0
+	now := time.Now()
+	for _, p := range m.projects {
+		if p.err != nil {
+			lines = append(lines, cBlue.Render("── "+p.name)+" "+cRed.Render(p.err.Error()))
+			continue
+		}
+		if len(p.items) == 0 {
+			continue
+		}
+		lines = append(lines, cBlue.Render(fmt.Sprintf("── %s (%d)", p.name, len(p.items)))+" "+cGray.Render(filepath.Dir(p.path)))
+		for _, item := range p.items {
+			e := item.Entry
+			prefix := "  "
+			if row == m.cursor {
+				prefix = cYellow.Render("> ")
+				cursorLine = len(lines)
+			}
+			lines = append(lines, fmt.Sprintf("%s%s%s [ %s%s ] - %s%s%s",
+				prefix,
+				strings.Repeat("  ", item.Depth),
+				cGray.Render(p.shortIDs[e.ID]),
+				cGreen.Render(strings.ToUpper(core.StatusOf(e, p.workflow))),
+				renderPriority(e.Priority),
+				e.Text,
+				renderTags(e.Tags),
+				renderDue(e, now)))
+			row++
+		}
+	}
+	return lines, cursorLine
+}
+
+func (m dashboardModel) View() string {
+	lines, _ := m.renderLines()
+	if len(m.rows) == 0 {
+		lines = append(lines, cGray.Render("Nothing open in any project."))
+	}
+	offset := min(m.offset, len(lines))
+	end := min(len(lines), offset+m.listHeight())
+
+	title := cMagenta.Render(fmt.Sprintf("Dashboard: %d open todos in %d projects", len(m.rows), len(m.projects)))
+	help := cGray.Render("↑/↓ move | Enter/Space complete | ^Z undo | r reload | q quit")
+	if m.confirm {
+		help = fmt.Sprintf("%s has %d open subtasks. %s", cYellow.Render(m.rows[m.cursor].entry.Text), len(m.openSubtasks()),
+			cGray.Render("a: complete all | p: only this task | any other key to cancel"))
+	}
+	return fmt.Sprintf("%s\n\n%s\n%s\n%s", title, strings.Join(lines[offset:end], "\n"), m.msg, help)
+}
+
+func (c *cli) runDashboard(args []string) error {
+	if len(args) > 0 {
+		return usageError{fmt.Sprintf("unexpected argument %q", args[0])}
+	}
+	m, err := newDashboard()
+	if err != nil {
+		return err
+	}
+	_, err = tea.NewProgram(m, tea.WithAltScreen()).Run()
+	return err
+}
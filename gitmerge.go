@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/skipperoo/tuido/internal/core"
+)
+
+// Git integration: a merge driver that resolves .tuido conflicts by entry ID
+
+const (
+	mergeDriverName    = "tuido"
+	mergeDriverCommand = "tuido merge-driver %O %A %B"
+)
+
+// gitAttributesLines map the YAML data file, the event log and the monthly
+// archive files to the driver
+var gitAttributesLines = []string{
+	".tuido merge=" + mergeDriverName,
+	".tuido.jsonl merge=" + mergeDriverName,
+	".tuido.archive/*.yaml merge=" + mergeDriverName,
+}
+
+// runMergeDriver merges the versions git hands to a merge driver and writes
+// the result over the current branch's version, as git expects
+func (c *cli) runMergeDriver(args []string) error {
+	if len(args) != 3 {
+		return usageError{"usage: merge-driver <base> <ours> <theirs>"}
+	}
+
+	var data [3][]byte
+	for i, path := range args {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		data[i] = content
+	}
+	if core.IsEventLog(data[1]) || core.IsEventLog(data[2]) {
+		merged, conflicts, err := core.MergeEventLogs(data[0], data[1], data[2])
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(args[1], merged, 0644); err != nil {
+			return err
+		}
+		entries, err := core.LoadEntries(args[1])
+		if err != nil {
+			return err
+		}
+		reportConflicts(c.stderr, entries, conflicts)
+		return nil
+	}
+
+	var versions [3][]core.Entry
+	for i, path := range args {
+		entries, err := core.LoadEntries(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		versions[i] = entries
+	}
+
+	result := core.Merge(versions[0], versions[1], versions[2])
+	reportConflicts(c.stderr, result.Entries, result.Conflicts)
+	return core.WriteEntries(args[1], result.Entries)
+}
+
+// reportConflicts warns about fields both sides of a merge changed
+func reportConflicts(w io.Writer, entries []core.Entry, conflicts []core.MergeConflict) {
+	short := core.ShortIDs(entries)
+	for _, conflict := range conflicts {
+		fmt.Fprintf(w, "tuido: both sides changed %s of entry %s, keeping ours\n",
+			conflict.Field, short[conflict.ID])
+	}
+}
+
+// runInstallMergeDriver registers the merge driver in git config and maps
+// .tuido files to it in the repository's .gitattributes
+func (c *cli) runInstallMergeDriver(args []string) error {
+	fs := c.newFlagSet("install-merge-driver")
+	global := fs.Bool("global", false, "register the driver in the global git config")
+	if err := c.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usageError{fmt.Sprintf("unexpected argument %q", fs.Arg(0))}
+	}
+
+	root, err := git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return fmt.Errorf("not inside a git repository: %w", err)
+	}
+
+	scope := "--local"
+	if *global {
+		scope = "--global"
+	}
+	settings := [][2]string{
+		{"merge." + mergeDriverName + ".name", "tuido entry merge"},
+		{"merge." + mergeDriverName + ".driver", mergeDriverCommand},
+	}
+	for _, kv := range settings {
+		if _, err := git("config", scope, kv[0], kv[1]); err != nil {
+			return err
+		}
+	}
+
+	attrPath := filepath.Join(root, ".gitattributes")
+	fmt.Fprintf(c.stdout, "configured merge.%s.driver (%s)\n", mergeDriverName, strings.TrimPrefix(scope, "--"))
+	for _, line := range gitAttributesLines {
+		added, err := ensureLine(attrPath, line)
+		if err != nil {
+			return err
+		}
+		if added {
+			fmt.Fprintf(c.stdout, "added %q to %s, commit it to share the setup\n", line, attrPath)
+		} else {
+			fmt.Fprintf(c.stdout, "%s already has %q\n", attrPath, line)
+		}
+	}
+	return nil
+}
+
+// git runs a git command and returns its trimmed stdout
+func git(args ...string) (string, error) {
+	out, err := exec.Command("git", args...).Output()
+	if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+		return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return strings.TrimSpace(string(out)), err
+}
+
+// ensureLine appends line to the file unless it is already present
+func ensureLine(path, line string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	for _, l := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(l) == line {
+			return false, nil
+		}
+	}
+
+	content := string(data)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return true, os.WriteFile(path, []byte(content+line+"\n"), 0644)
+}
@@ -0,0 +1,159 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const archiveDirSuffix = ".archive"
+
+// DefaultArchiveDays is how long completed todos stay in the data file when
+// the project config does not set archive_days
+const DefaultArchiveDays = 30
+
+// ArchiveDir returns the directory holding the archive files of a data file
+func ArchiveDir(path string) string {
+	return strings.TrimSuffix(path, eventLogExt) + archiveDirSuffix
+}
+
+// SplitArchivable splits off the completed todos finished before cutoff and
+// marks them as archived. A todo stays while any of its subtasks stays, so
+// archiving never tears a tree apart.
+func SplitArchivable(entries []Entry, cutoff time.Time) (keep, archived []Entry) {
+	candidate := map[string]bool{}
+	for _, e := range entries {
+		if e.Type == TypeTodo && e.CompletedAt != nil && e.CompletedAt.Before(cutoff) && !e.Trashed() {
+			candidate[e.ID] = true
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, e := range entries {
+			if e.ParentID != "" && candidate[e.ParentID] && !candidate[e.ID] {
+				delete(candidate, e.ParentID)
+				changed = true
+			}
+		}
+	}
+
+	now := time.Now()
+	keep = []Entry{}
+	for _, e := range entries {
+		if candidate[e.ID] {
+			e.ArchivedAt = &now
+			archived = append(archived, e)
+		} else {
+			keep = append(keep, e)
+		}
+	}
+	return keep, archived
+}
+
+// archiveFile returns the archive file for the month a todo was completed in,
+// e.g. .tuido.archive/2026-10.yaml
+func archiveFile(path string, e Entry) string {
+	return filepath.Join(ArchiveDir(path), e.CompletedAt.UTC().Format("2006-01")+".yaml")
+}
+
+// WriteArchive adds entries to the archive files of a data file, one file per
+// month of completion. Entries already in their file are replaced.
+func WriteArchive(path string, entries []Entry) error {
+	byFile := map[string][]Entry{}
+	for _, e := range entries {
+		file := archiveFile(path, e)
+		byFile[file] = append(byFile[file], e)
+	}
+	if len(byFile) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(ArchiveDir(path), 0755); err != nil {
+		return err
+	}
+
+	for file, added := range byFile {
+		existing, err := readArchiveFile(file)
+		if err != nil {
+			return err
+		}
+		merged := dedupeEntries(append(existing, added...))
+		sort.SliceStable(merged, func(i, j int) bool {
+			return merged[i].CompletedAt.Before(*merged[j].CompletedAt)
+		})
+		data, err := yaml.Marshal(merged)
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(file, data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readArchiveFile(file string) ([]Entry, error) {
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	entries, err := parseEntries(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(file), err)
+	}
+	return entries, nil
+}
+
+// LoadArchive returns the archived entries of a data file, oldest month first
+func LoadArchive(path string) ([]Entry, error) {
+	files, err := filepath.Glob(filepath.Join(ArchiveDir(path), "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var archived []Entry
+	for _, file := range files {
+		entries, err := readArchiveFile(file)
+		if err != nil {
+			return nil, err
+		}
+		archived = append(archived, entries...)
+	}
+	return dedupeEntries(archived), nil
+}
+
+// WithArchive appends the archived entries that are not also in entries,
+// which happens when archiving was undone
+func WithArchive(entries, archived []Entry) []Entry {
+	present := indexEntries(entries)
+	all := append([]Entry{}, entries...)
+	for _, e := range archived {
+		if _, ok := present[e.ID]; !ok {
+			all = append(all, e)
+		}
+	}
+	return all
+}
+
+// dedupeEntries keeps the last copy of each entry, in the position of the first
+func dedupeEntries(entries []Entry) []Entry {
+	at := map[string]int{}
+	var deduped []Entry
+	for _, e := range entries {
+		if i, ok := at[e.ID]; ok {
+			deduped[i] = e
+			continue
+		}
+		at[e.ID] = len(deduped)
+		deduped = append(deduped, e)
+	}
+	return deduped
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSplitArchivable(t *testing.T) {
+	old := time.Now().AddDate(0, 0, -60)
+	recent := time.Now().AddDate(0, 0, -1)
+	entries := AddEntry([]Entry{}, "Old", "User", TypeTodo)
+	entries = AddEntry(entries, "Recent", "User", TypeTodo)
+	entries = AddEntry(entries, "Open", "User", TypeTodo)
+	entries = AddEntry(entries, "Old parent", "User", TypeTodo)
+	entries = AddEntry(entries, "Open subtask", "User", TypeTodo)
+	entries = AddEntry(entries, "Note", "User", TypeNote)
+	entries[0].CompletedAt = &old
+	entries[1].CompletedAt = &recent
+	entries[3].CompletedAt = &old
+	entries[4].ParentID = entries[3].ID
+
+	keep, archived := SplitArchivable(entries, time.Now().AddDate(0, 0, -30))
+	if len(archived) != 1 || archived[0].Text != "Old" || archived[0].ArchivedAt == nil {
+		t.Errorf("Expected only the old todo to be archived, got %v", archived)
+	}
+	if len(keep) != 5 {
+		t.Errorf("Expected the other entries to stay, got %v", keep)
+	}
+}
+
+func TestWriteAndLoadArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), dataFileName)
+	october := time.Date(2026, 10, 5, 12, 0, 0, 0, time.UTC)
+	september := time.Date(2026, 9, 20, 12, 0, 0, 0, time.UTC)
+	entries := AddEntry([]Entry{}, "October", "User", TypeTodo)
+	entries = AddEntry(entries, "September", "User", TypeTodo)
+	entries[0].CompletedAt = &october
+	entries[1].CompletedAt = &september
+
+	if err := WriteArchive(path, entries[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteArchive(path, entries); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"2026-09.yaml", "2026-10.yaml"} {
+		if _, err := os.Stat(filepath.Join(ArchiveDir(path), name)); err != nil {
+			t.Errorf("Expected archive file %s: %v", name, err)
+		}
+	}
+
+	archived, err := LoadArchive(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archived) != 2 || archived[0].Text != "September" || archived[1].Text != "October" {
+		t.Errorf("Expected each entry once, oldest month first, got %v", archived)
+	}
+	if all := WithArchive(entries[:1], archived); len(all) != 2 {
+		t.Errorf("Expected entries in both places to appear once, got %v", all)
+	}
+}
+
+func TestQueryInArchive(t *testing.T) {
+	q, err := ParseQuery("in:archive deploy")
+	if err != nil || !q.IncludesArchive() {
+		t.Errorf("Expected in:archive to include the archive, got %v", err)
+	}
+	if _, err := ParseQuery("-in:archive"); err == nil {
+		t.Error("Expected -in:archive to be rejected")
+	}
+}
@@ -0,0 +1,272 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var ErrDependencyCycle = errors.New("dependency would create a cycle")
+
+// GraphFormat selects how GenerateGraph renders dependencies
+type GraphFormat string
+
+const (
+	GraphDOT     GraphFormat = "dot"
+	GraphMermaid GraphFormat = "mermaid"
+)
+
+// ParseGraphFormat validates a graph format name
+func ParseGraphFormat(s string) (GraphFormat, error) {
+	switch f := GraphFormat(strings.ToLower(s)); f {
+	case GraphDOT, GraphMermaid:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown graph format %q (want dot or mermaid)", s)
+}
+
+// AddBlocker records that id cannot start until blockerID is done
+func AddBlocker(entries []Entry, id string, blockerID string) ([]Entry, error) {
+	if id == blockerID {
+		return entries, ErrDependencyCycle
+	}
+	if _, ok := FindEntry(entries, blockerID); !ok {
+		return entries, fmt.Errorf("no entry with id %q", blockerID)
+	}
+	// blockerID waiting on id, directly or not, would close a loop
+	if dependsOn(entries, blockerID, id) {
+		return entries, ErrDependencyCycle
+	}
+
+	for i, e := range entries {
+		if e.ID == id {
+			if !slices.Contains(e.BlockedBy, blockerID) {
+				entries[i].BlockedBy = append(slices.Clone(e.BlockedBy), blockerID)
+			}
+			return entries, nil
+		}
+	}
+	return entries, fmt.Errorf("no entry with id %q", id)
+}
+
+// RemoveBlocker drops blockerID from the blockers of id; an empty blockerID drops them all
+func RemoveBlocker(entries []Entry, id string, blockerID string) []Entry {
+	for i, e := range entries {
+		if e.ID != id {
+			continue
+		}
+		var kept []string
+		for _, b := range e.BlockedBy {
+			if blockerID != "" && b != blockerID {
+				kept = append(kept, b)
+			}
+		}
+		entries[i].BlockedBy = kept
+		return entries
+	}
+	return entries
+}
+
+// dependsOn reports whether id is blocked by target, directly or transitively
+func dependsOn(entries []Entry, id string, target string) bool {
+	byID := indexEntries(entries)
+	seen := make(map[string]bool)
+	stack := []string{id}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[cur] {
+			continue
+		}
+		seen[cur] = true
+		for _, b := range byID[cur].BlockedBy {
+			if b == target {
+				return true
+			}
+			stack = append(stack, b)
+		}
+	}
+	return false
+}
+
+// OpenBlockers returns the blockers of e that are still open todos.
+// Blockers that were removed or trashed no longer block anything.
+func OpenBlockers(entries []Entry, e Entry) []Entry {
+	var open []Entry
+	for _, id := range e.BlockedBy {
+		b, ok := FindEntry(entries, id)
+		if ok && b.Type == TypeTodo && b.CompletedAt == nil && !b.Trashed() {
+			open = append(open, b)
+		}
+	}
+	return open
+}
+
+// IsBlocked reports whether e is an open todo waiting on open blockers
+func IsBlocked(entries []Entry, e Entry) bool {
+	return e.Type == TypeTodo && e.CompletedAt == nil && len(OpenBlockers(entries, e)) > 0
+}
+
+// FindCycles returns each group of entries that block one another in a loop,
+// as lists of IDs in list order. Only files edited by hand or merged from
+// diverging branches can contain cycles, since AddBlocker refuses them.
+func FindCycles(entries []Entry) [][]string {
+	byID := indexEntries(entries)
+
+	// Tarjan's strongly connected components
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var groups [][]string
+	next := 0
+
+	var visit func(id string)
+	visit = func(id string) {
+		index[id] = next
+		low[id] = next
+		next++
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, b := range byID[id].BlockedBy {
+			if _, ok := byID[b]; !ok {
+				continue
+			}
+			if _, seen := index[b]; !seen {
+				visit(b)
+				low[id] = min(low[id], low[b])
+			} else if onStack[b] {
+				low[id] = min(low[id], index[b])
+			}
+		}
+
+		if low[id] != index[id] {
+			return
+		}
+		var group []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			group = append(group, top)
+			if top == id {
+				break
+			}
+		}
+		if len(group) > 1 || slices.Contains(byID[id].BlockedBy, id) {
+			groups = append(groups, group)
+		}
+	}
+	for _, e := range entries {
+		if _, seen := index[e.ID]; !seen {
+			visit(e.ID)
+		}
+	}
+
+	// Report members in list order so the output is stable
+	order := make(map[string]int, len(entries))
+	for i, e := range entries {
+		order[e.ID] = i
+	}
+	for _, g := range groups {
+		slices.SortFunc(g, func(a, b string) int { return order[a] - order[b] })
+	}
+	slices.SortFunc(groups, func(a, b []string) int { return order[a[0]] - order[b[0]] })
+	return groups
+}
+
+// GenerateGraph renders the dependency graph of entries that block or are
+// blocked by something. Edges point from a blocker to the task it blocks;
+// edges inside a cycle are highlighted.
+func GenerateGraph(entries []Entry, format GraphFormat) string {
+	short := ShortIDs(entries)
+	byID := indexEntries(entries)
+	inCycle := make(map[string]int)
+	for i, g := range FindCycles(entries) {
+		for _, id := range g {
+			inCycle[id] = i + 1
+		}
+	}
+
+	// Nodes taking part in at least one edge, in list order
+	linked := make(map[string]bool)
+	type edge struct{ from, to string }
+	var edges []edge
+	for _, e := range entries {
+		for _, b := range e.BlockedBy {
+			if _, ok := byID[b]; !ok {
+				continue
+			}
+			linked[e.ID], linked[b] = true, true
+			edges = append(edges, edge{from: b, to: e.ID})
+		}
+	}
+
+	var sb strings.Builder
+	switch format {
+	case GraphMermaid:
+		sb.WriteString("graph LR\n")
+		for _, e := range entries {
+			if linked[e.ID] {
+				fmt.Fprintf(&sb, "  %s[\"%s\"]\n", mermaidID(short[e.ID]), mermaidEscaper.Replace(graphLabel(e, short[e.ID])))
+			}
+		}
+		var cycleLinks []string
+		for i, ed := range edges {
+			fmt.Fprintf(&sb, "  %s --> %s\n", mermaidID(short[ed.from]), mermaidID(short[ed.to]))
+			if inCycle[ed.from] != 0 && inCycle[ed.from] == inCycle[ed.to] {
+				cycleLinks = append(cycleLinks, fmt.Sprint(i))
+			}
+		}
+		for _, e := range entries {
+			if linked[e.ID] && e.CompletedAt != nil {
+				fmt.Fprintf(&sb, "  class %s done\n", mermaidID(short[e.ID]))
+			}
+		}
+		sb.WriteString("  classDef done fill:#ddd,color:#777\n")
+		if len(cycleLinks) > 0 {
+			fmt.Fprintf(&sb, "  linkStyle %s stroke:red\n", strings.Join(cycleLinks, ","))
+		}
+	default:
+		sb.WriteString("digraph tuido {\n  rankdir=LR;\n  node [shape=box];\n")
+		for _, e := range entries {
+			if !linked[e.ID] {
+				continue
+			}
+			attrs := ""
+			if e.CompletedAt != nil {
+				attrs = ", style=filled, fillcolor=lightgray, fontcolor=gray40"
+			}
+			fmt.Fprintf(&sb, "  %q [label=\"%s\"%s];\n", short[e.ID], dotEscaper.Replace(graphLabel(e, short[e.ID])), attrs)
+		}
+		for _, ed := range edges {
+			attrs := ""
+			if inCycle[ed.from] != 0 && inCycle[ed.from] == inCycle[ed.to] {
+				attrs = " [color=red]"
+			}
+			fmt.Fprintf(&sb, "  %q -> %q%s;\n", short[ed.from], short[ed.to], attrs)
+		}
+		sb.WriteString("}\n")
+	}
+	return sb.String()
+}
+
+var (
+	dotEscaper     = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	mermaidEscaper = strings.NewReplacer(`"`, `#quot;`)
+)
+
+// graphLabel is the node text before escaping
+func graphLabel(e Entry, short string) string {
+	if e.CompletedAt != nil {
+		return short + ": " + e.Text + " (done)"
+	}
+	return short + ": " + e.Text
+}
+
+// mermaidID prefixes short IDs so ones that are all digits stay valid node names
+func mermaidID(short string) string {
+	return "t" + short
+}
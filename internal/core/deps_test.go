@@ -0,0 +1,111 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAddBlocker(t *testing.T) {
+	entries := []Entry{{ID: "a", Type: TypeTodo}, {ID: "b", Type: TypeTodo}, {ID: "c", Type: TypeTodo}}
+
+	entries, err := AddBlocker(entries, "b", "a")
+	if err != nil || len(entries[1].BlockedBy) != 1 || entries[1].BlockedBy[0] != "a" {
+		t.Fatalf("AddBlocker failed: %v %v", entries[1].BlockedBy, err)
+	}
+	entries, _ = AddBlocker(entries, "b", "a")
+	if len(entries[1].BlockedBy) != 1 {
+		t.Error("Expected duplicate blockers to be ignored")
+	}
+	entries, _ = AddBlocker(entries, "c", "b")
+
+	if _, err := AddBlocker(entries, "a", "c"); !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("Expected ErrDependencyCycle, got %v", err)
+	}
+	if _, err := AddBlocker(entries, "a", "a"); !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("Expected ErrDependencyCycle for self, got %v", err)
+	}
+	if _, err := AddBlocker(entries, "a", "missing"); err == nil {
+		t.Error("Expected error for a missing blocker")
+	}
+
+	entries = RemoveBlocker(entries, "c", "b")
+	if len(entries[2].BlockedBy) != 0 {
+		t.Errorf("Expected blocker removed, got %v", entries[2].BlockedBy)
+	}
+}
+
+func TestOpenBlockers(t *testing.T) {
+	entries := []Entry{}
+	entries = AddEntry(entries, "Design", "User", TypeTodo)
+	entries = AddEntry(entries, "Review", "User", TypeTodo)
+	entries = AddEntry(entries, "Build", "User", TypeTodo)
+	entries, _ = AddBlocker(entries, entries[2].ID, entries[0].ID)
+	entries, _ = AddBlocker(entries, entries[2].ID, entries[1].ID)
+
+	if !IsBlocked(entries, entries[2]) || len(OpenBlockers(entries, entries[2])) != 2 {
+		t.Fatal("Expected Build to be blocked by two tasks")
+	}
+	entries = MarkDone(entries, entries[0].ID, "User")
+	entries = RemoveEntry(entries, entries[1].ID)
+	if IsBlocked(entries, entries[1]) {
+		t.Error("Expected done and removed blockers not to block")
+	}
+}
+
+func TestFindCycles(t *testing.T) {
+	entries := []Entry{
+		{ID: "a", BlockedBy: []string{"c"}},
+		{ID: "b", BlockedBy: []string{"a"}},
+		{ID: "c", BlockedBy: []string{"b"}},
+		{ID: "d", BlockedBy: []string{"a", "gone"}},
+		{ID: "e", BlockedBy: []string{"e"}},
+	}
+	cycles := FindCycles(entries)
+	if len(cycles) != 2 {
+		t.Fatalf("Expected 2 cycles, got %v", cycles)
+	}
+	if strings.Join(cycles[0], ",") != "a,b,c" || strings.Join(cycles[1], ",") != "e" {
+		t.Errorf("Unexpected cycles: %v", cycles)
+	}
+	if len(FindCycles(entries[3:4])) != 0 {
+		t.Error("Expected no cycles")
+	}
+}
+
+func TestGenerateGraph(t *testing.T) {
+	entries := []Entry{}
+	entries = AddEntry(entries, `Write "spec"`, "User", TypeTodo)
+	entries = AddEntry(entries, "Implement", "User", TypeTodo)
+	entries = AddEntry(entries, "Unrelated", "User", TypeTodo)
+	entries, _ = AddBlocker(entries, entries[1].ID, entries[0].ID)
+	short := ShortIDs(entries)
+
+	dot := GenerateGraph(entries, GraphDOT)
+	if !strings.Contains(dot, `"`+short[entries[0].ID]+`" -> "`+short[entries[1].ID]+`";`) {
+		t.Errorf("Missing DOT edge:\n%s", dot)
+	}
+	if !strings.Contains(dot, `Write \"spec\"`) || strings.Contains(dot, "Unrelated") {
+		t.Errorf("Unexpected DOT nodes:\n%s", dot)
+	}
+
+	mermaid := GenerateGraph(entries, GraphMermaid)
+	if !strings.HasPrefix(mermaid, "graph LR\n") || !strings.Contains(mermaid, "t"+short[entries[0].ID]+" --> t"+short[entries[1].ID]) {
+		t.Errorf("Missing Mermaid edge:\n%s", mermaid)
+	}
+
+	// Cycles only come from hand edits or merges
+	entries[0].BlockedBy = []string{entries[1].ID}
+	if !strings.Contains(GenerateGraph(entries, GraphDOT), "[color=red]") {
+		t.Error("Expected cycle edges to be highlighted")
+	}
+}
+
+func TestParseGraphFormat(t *testing.T) {
+	if f, err := ParseGraphFormat("Mermaid"); err != nil || f != GraphMermaid {
+		t.Errorf("Expected mermaid, got %q, %v", f, err)
+	}
+	if _, err := ParseGraphFormat("svg"); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dueDateLayout = "2006-01-02"
+
+type DueState int
+
+const (
+	DueNone DueState = iota
+	DueLater
+	DueToday
+	DueOverdue
+)
+
+// ParseDue turns a due date expression into the start of the day it names:
+//
+//	today, tomorrow        relative days
+//	monday .. sunday       the next such day (today counts), also mon .. sun
+//	2026-11-01             an absolute date
+//	+3d, +2w, +1m          days, weeks or months from today
+func ParseDue(s string, now time.Time) (time.Time, error) {
+	expr := strings.ToLower(strings.TrimSpace(s))
+	today := startOfDay(now)
+
+	switch expr {
+	case "today":
+		return today, nil
+	case "tomorrow", "tmr":
+		return today.AddDate(0, 0, 1), nil
+	}
+
+	if wd, ok := parseWeekday(expr); ok {
+		days := (int(wd) - int(today.Weekday()) + 7) % 7
+		return today.AddDate(0, 0, days), nil
+	}
+
+	if strings.HasPrefix(expr, "+") && len(expr) > 2 {
+		n, err := strconv.Atoi(expr[1 : len(expr)-1])
+		if err == nil && n >= 0 {
+			switch expr[len(expr)-1] {
+			case 'd':
+				return today.AddDate(0, 0, n), nil
+			case 'w':
+				return today.AddDate(0, 0, 7*n), nil
+			case 'm':
+				return today.AddDate(0, n, 0), nil
+			}
+		}
+	}
+
+	if t, err := time.ParseInLocation(dueDateLayout, expr, now.Location()); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("cannot parse due date %q", s)
+}
+
+func parseWeekday(s string) (time.Weekday, bool) {
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		name := strings.ToLower(wd.String())
+		if s == name || s == name[:3] {
+			return wd, true
+		}
+	}
+	return time.Sunday, false
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// parseDueToken recognizes inline due tokens like due:friday
+func parseDueToken(token string, now time.Time) (time.Time, bool) {
+	expr, ok := strings.CutPrefix(strings.ToLower(token), "due:")
+	if !ok {
+		return time.Time{}, false
+	}
+	due, err := ParseDue(expr, now)
+	return due, err == nil
+}
+
+// FormatDue renders a due date the way ParseDue accepts it
+func FormatDue(t time.Time) string {
+	return t.Format(dueDateLayout)
+}
+
+// GetDueState reports whether an open todo is overdue, due today or due later
+func GetDueState(e Entry, now time.Time) DueState {
+	if e.DueAt == nil || e.CompletedAt != nil {
+		return DueNone
+	}
+	today := startOfDay(now)
+	due := startOfDay(e.DueAt.In(now.Location()))
+	switch {
+	case due.Before(today):
+		return DueOverdue
+	case due.Equal(today):
+		return DueToday
+	}
+	return DueLater
+}
+
+// SetDue changes the due date of an entry; nil clears it
+func SetDue(entries []Entry, id string, due *time.Time) []Entry {
+	for i, e := range entries {
+		if e.ID == id {
+			entries[i].DueAt = due
+			return entries
+		}
+	}
+	return entries
+}
+
+// GetUpcomingTodos returns open todos with a due date, soonest first
+func GetUpcomingTodos(entries []Entry) []Entry {
+	var upcoming []Entry
+	for _, e := range GetActiveTodos(entries) {
+		if e.DueAt != nil {
+			upcoming = append(upcoming, e)
+		}
+	}
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return upcoming[i].DueAt.Before(*upcoming[j].DueAt)
+	})
+	return upcoming
+}
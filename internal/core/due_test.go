@@ -0,0 +1,109 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+// Friday, 2026-10-16 at noon
+var dueNow = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+func TestParseDue(t *testing.T) {
+	cases := map[string]string{
+		"today":      "2026-10-16",
+		"tomorrow":   "2026-10-17",
+		"friday":     "2026-10-16",
+		"mon":        "2026-10-19",
+		"Thursday":   "2026-10-22",
+		"+3d":        "2026-10-19",
+		"+2w":        "2026-10-30",
+		"+1m":        "2026-11-16",
+		"2026-11-01": "2026-11-01",
+	}
+	for in, want := range cases {
+		got, err := ParseDue(in, dueNow)
+		if err != nil {
+			t.Errorf("ParseDue(%q) failed: %v", in, err)
+			continue
+		}
+		if FormatDue(got) != want {
+			t.Errorf("ParseDue(%q) = %s, want %s", in, FormatDue(got), want)
+		}
+		if got.Hour() != 0 || got.Minute() != 0 {
+			t.Errorf("ParseDue(%q) should return the start of the day, got %v", in, got)
+		}
+	}
+
+	for _, in := range []string{"", "someday", "+d", "+-1d", "2026-13-01"} {
+		if _, err := ParseDue(in, dueNow); err == nil {
+			t.Errorf("Expected ParseDue(%q) to fail", in)
+		}
+	}
+}
+
+func TestAddEntryDue(t *testing.T) {
+	entries := AddEntry([]Entry{}, "Ship it due:+1d !high", "User", TypeTodo)
+	if entries[0].DueAt == nil {
+		t.Fatal("Expected due date to be set")
+	}
+	if entries[0].Text != "Ship it" {
+		t.Errorf("Expected 'Ship it', got '%s'", entries[0].Text)
+	}
+
+	entries = AddEntry(entries, "Ship it due:someday", "User", TypeTodo)
+	if entries[1].DueAt != nil || entries[1].Text != "Ship it due:someday" {
+		t.Error("Expected unparseable due token to stay in text")
+	}
+}
+
+func TestGetDueState(t *testing.T) {
+	day := func(offset int) *time.Time {
+		d := startOfDay(dueNow).AddDate(0, 0, offset)
+		return &d
+	}
+
+	cases := []struct {
+		entry Entry
+		want  DueState
+	}{
+		{Entry{Type: TypeTodo}, DueNone},
+		{Entry{Type: TypeTodo, DueAt: day(-1)}, DueOverdue},
+		{Entry{Type: TypeTodo, DueAt: day(0)}, DueToday},
+		{Entry{Type: TypeTodo, DueAt: day(1)}, DueLater},
+		{Entry{Type: TypeTodo, DueAt: day(-1), CompletedAt: &dueNow}, DueNone},
+	}
+	for i, c := range cases {
+		if got := GetDueState(c.entry, dueNow); got != c.want {
+			t.Errorf("Case %d: expected %v, got %v", i, c.want, got)
+		}
+	}
+}
+
+func TestSetDue(t *testing.T) {
+	entries := AddEntry([]Entry{}, "Task", "User", TypeTodo)
+	entries = SetDue(entries, entries[0].ID, &dueNow)
+	if entries[0].DueAt == nil {
+		t.Fatal("Expected due date to be set")
+	}
+	entries = SetDue(entries, entries[0].ID, nil)
+	if entries[0].DueAt != nil {
+		t.Error("Expected due date to be cleared")
+	}
+}
+
+func TestGetUpcomingTodos(t *testing.T) {
+	entries := []Entry{}
+	entries = AddEntry(entries, "Later due:+5d", "User", TypeTodo)
+	entries = AddEntry(entries, "No date", "User", TypeTodo)
+	entries = AddEntry(entries, "Soon due:today", "User", TypeTodo)
+	entries = AddEntry(entries, "Done due:today", "User", TypeTodo)
+	entries = MarkDone(entries, entries[3].ID, "User")
+
+	upcoming := GetUpcomingTodos(entries)
+	if len(upcoming) != 2 {
+		t.Fatalf("Expected 2 upcoming todos, got %d", len(upcoming))
+	}
+	if upcoming[0].Text != "Soon" || upcoming[1].Text != "Later" {
+		t.Errorf("Unexpected order: %s, %s", upcoming[0].Text, upcoming[1].Text)
+	}
+}
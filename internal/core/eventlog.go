@@ -0,0 +1,400 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// An event log is an alternative to the YAML list: a data file whose name
+// ends in .jsonl holds one JSON event per line. Loading replays the events;
+// saving appends the events that turn the replayed list into the new one.
+
+const eventLogExt = ".jsonl"
+
+// CompactThreshold is the number of events after the last snapshot at which
+// a save folds the log into a single snapshot
+var CompactThreshold = 1000
+
+// Event ops besides the change labels add, remove, done, undone, edit and update
+const (
+	eventMove     = "move"
+	eventRevise   = "revise"
+	eventSnapshot = "snapshot"
+)
+
+// Event is one line of an event log
+type Event struct {
+	ID      string                     `json:"id"` // Unique, so merged logs can drop duplicates
+	At      time.Time                  `json:"at"`
+	Op      string                     `json:"op"`
+	EntryID string                     `json:"entry,omitempty"`
+	Index   int                        `json:"index,omitempty"`   // Position of an added or moved entry
+	Data    *Entry                     `json:"data,omitempty"`    // The added entry
+	Set     map[string]json.RawMessage `json:"set,omitempty"`     // Changed fields by JSON name; null clears one
+	Entries []Entry                    `json:"entries,omitempty"` // The whole list, for a snapshot
+	// Revisions appended to the entry. They are their own event, so merged
+	// logs keep the revisions recorded on both sides.
+	Revisions []Revision `json:"revisions,omitempty"`
+}
+
+// IsEventLogPath reports whether a data file is stored as an event log
+func IsEventLogPath(path string) bool {
+	return strings.HasSuffix(path, eventLogExt)
+}
+
+// IsEventLog reports whether data is an event log rather than a YAML list.
+// Files git hands to a merge driver have no telling name, so this sniffs the content.
+func IsEventLog(data []byte) bool {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+// parseEvents decodes an event log. A last line without a newline that does
+// not parse was torn by a crash mid-append and is ignored; the returned
+// length is that of the data before it.
+func parseEvents(data []byte) ([]Event, int, error) {
+	var events []Event
+	valid := len(data)
+	for n, offset := 1, 0; offset < len(data); n++ {
+		end := bytes.IndexByte(data[offset:], '\n')
+		terminated := end >= 0
+		if !terminated {
+			end = len(data) - offset
+		}
+		line := bytes.TrimSpace(data[offset : offset+end])
+		if len(line) > 0 {
+			var ev Event
+			if err := json.Unmarshal(line, &ev); err != nil {
+				if !terminated {
+					valid = offset
+					break
+				}
+				return nil, 0, fmt.Errorf("line %d: %w", n, err)
+			}
+			events = append(events, ev)
+		}
+		offset += end + 1
+	}
+	return events, valid, nil
+}
+
+// replayEvents rebuilds the list from an event log. It also returns the
+// number of events after the last snapshot.
+func replayEvents(data []byte) ([]Entry, int, error) {
+	events, _, err := parseEvents(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	entries := []Entry{}
+	pending := 0
+	for _, ev := range events {
+		entries = applyEvent(entries, ev)
+		pending++
+		if ev.Op == eventSnapshot {
+			pending = 0
+		}
+	}
+	return MigrateEntries(entries), pending, nil
+}
+
+// applyEvent applies one event. Events about entries that no longer exist are
+// ignored, which happens when merged logs removed and changed the same entry.
+func applyEvent(entries []Entry, ev Event) []Entry {
+	i := slices.IndexFunc(entries, func(e Entry) bool { return e.ID == ev.EntryID })
+	switch ev.Op {
+	case eventSnapshot:
+		return append([]Entry{}, ev.Entries...)
+	case "add":
+		if ev.Data != nil && i < 0 {
+			at := min(max(ev.Index, 0), len(entries))
+			return slices.Insert(entries, at, *ev.Data)
+		}
+	case "remove":
+		if i >= 0 {
+			return slices.Delete(entries, i, i+1)
+		}
+	case eventRevise:
+		if i >= 0 {
+			entries[i].Revisions = mergeRevisions(nil, ev.Revisions, entries[i].Revisions)
+		}
+	case eventMove:
+		if i >= 0 {
+			e := entries[i]
+			entries = slices.Delete(entries, i, i+1)
+			at := min(max(ev.Index, 0), len(entries))
+			return slices.Insert(entries, at, e)
+		}
+	default:
+		if i >= 0 && len(ev.Set) > 0 {
+			entries[i] = patchEntry(entries[i], ev.Set)
+		}
+	}
+	return entries
+}
+
+// entryFields returns the JSON encoding of an entry by field name
+func entryFields(e Entry) map[string]json.RawMessage {
+	// An Entry always encodes, so errors cannot happen here
+	data, _ := json.Marshal(e)
+	var fields map[string]json.RawMessage
+	json.Unmarshal(data, &fields)
+	return fields
+}
+
+// patchEntry overwrites the given fields of an entry
+func patchEntry(e Entry, set map[string]json.RawMessage) Entry {
+	fields := entryFields(e)
+	for name, value := range set {
+		if string(value) == "null" {
+			delete(fields, name)
+		} else {
+			fields[name] = value
+		}
+	}
+	data, _ := json.Marshal(fields)
+	var patched Entry
+	if err := json.Unmarshal(data, &patched); err != nil {
+		return e
+	}
+	return patched
+}
+
+// fieldChanges returns the fields that differ between two versions of an entry
+func fieldChanges(before, after Entry) map[string]json.RawMessage {
+	b, a := entryFields(before), entryFields(after)
+	set := map[string]json.RawMessage{}
+	for name, value := range a {
+		if !bytes.Equal(b[name], value) {
+			set[name] = value
+		}
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			set[name] = json.RawMessage("null")
+		}
+	}
+	return set
+}
+
+// diffEvents returns the events that turn current into entries: removals,
+// additions, field changes and finally moves for entries out of place
+func diffEvents(current, entries []Entry, now time.Time) []Event {
+	var events []Event
+	work := slices.Clone(current)
+	emit := func(ev Event) {
+		ev.ID = uuid.New().String()
+		ev.At = now
+		work = applyEvent(work, ev)
+		events = append(events, ev)
+	}
+
+	next := indexEntries(entries)
+	for _, e := range current {
+		if _, ok := next[e.ID]; !ok {
+			emit(Event{Op: "remove", EntryID: e.ID})
+		}
+	}
+	prev := indexEntries(current)
+	for i, e := range entries {
+		b, existed := prev[e.ID]
+		if !existed {
+			emit(Event{Op: "add", EntryID: e.ID, Index: i, Data: &e})
+		} else if !entriesEqual(b, e) {
+			set := fieldChanges(b, e)
+			appended, ok := appendedRevisions(b.Revisions, e.Revisions)
+			if ok {
+				delete(set, "revisions")
+			}
+			if len(set) > 0 {
+				emit(Event{Op: changeLabel(&b, &e), EntryID: e.ID, Set: set})
+			}
+			if len(appended) > 0 {
+				emit(Event{Op: eventRevise, EntryID: e.ID, Revisions: appended})
+			}
+		}
+	}
+	for i, e := range entries {
+		if i < len(work) && work[i].ID == e.ID {
+			continue
+		}
+		emit(Event{Op: eventMove, EntryID: e.ID, Index: i})
+	}
+	return events
+}
+
+// appendedRevisions returns the revisions after holds beyond before. It fails
+// if before is not where after started, e.g. when an undo took one back.
+func appendedRevisions(before, after []Revision) ([]Revision, bool) {
+	if len(after) < len(before) {
+		return nil, false
+	}
+	for i, r := range before {
+		if revisionKey(r) != revisionKey(after[i]) {
+			return nil, false
+		}
+	}
+	return after[len(before):], true
+}
+
+func newSnapshot(entries []Entry, now time.Time) Event {
+	return Event{ID: uuid.New().String(), At: now, Op: eventSnapshot, Entries: entries}
+}
+
+func marshalEvents(events []Event) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, ev := range events {
+		line, err := json.Marshal(ev)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// saveEventLog appends the events that turn the log into entries. Once more
+// than CompactThreshold events follow the last snapshot, or if the file still
+// holds a YAML list, the log is rewritten as one snapshot and the previous
+// file is kept as the backup.
+func saveEventLog(path string, entries []Entry) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) > 0 && !IsEventLog(data) {
+		return compactEventLog(path, data, entries)
+	}
+
+	current, pending, err := replayEvents(data)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCorrupt, err)
+	}
+	events := diffEvents(current, entries, time.Now())
+	if len(events) == 0 {
+		return nil
+	}
+	if pending+len(events) > CompactThreshold {
+		return compactEventLog(path, data, entries)
+	}
+	return appendEvents(path, data, events)
+}
+
+// appendEvents writes events at the end of the log, first dropping a line
+// torn by a crash during the previous append
+func appendEvents(path string, data []byte, events []Event) error {
+	lines, err := marshalEvents(events)
+	if err != nil {
+		return err
+	}
+	_, valid, err := parseEvents(data)
+	if err != nil {
+		return err
+	}
+	if valid > 0 && data[valid-1] != '\n' {
+		lines = append([]byte{'\n'}, lines...)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := f.Truncate(int64(valid)); err != nil {
+		return err
+	}
+	if _, err := f.Seek(int64(valid), io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := f.Write(lines); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// compactEventLog replaces the log with a snapshot of entries
+func compactEventLog(path string, data []byte, entries []Entry) error {
+	if len(data) > 0 {
+		if err := writeFileAtomic(BackupPath(path), data, 0644); err != nil {
+			return fmt.Errorf("backup failed: %w", err)
+		}
+	}
+	return writeEventLog(path, []Event{newSnapshot(entries, time.Now())})
+}
+
+func writeEventLog(path string, events []Event) error {
+	data, err := marshalEvents(events)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// MergeEventLogs merges two event logs derived from base. When both sides only
+// appended to base, the result is base followed by the events of both sides
+// in time order, so every change is kept and the later one wins a field both
+// changed. Otherwise, e.g. after one side compacted, the replayed lists are
+// merged like Merge does and the result is written as a snapshot.
+func MergeEventLogs(base, ours, theirs []byte) ([]byte, []MergeConflict, error) {
+	var events [3][]Event
+	var entries [3][]Entry
+	for i, data := range [][]byte{base, ours, theirs} {
+		decoded, err := decodeEntries(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		entries[i] = decoded
+		if IsEventLog(data) {
+			events[i], _, _ = parseEvents(data)
+		}
+	}
+
+	baseIsLog := IsEventLog(base) || len(bytes.TrimSpace(base)) == 0
+	if merged, ok := unionEvents(events[0], events[1], events[2]); ok && baseIsLog {
+		data, err := marshalEvents(merged)
+		return data, nil, err
+	}
+
+	result := Merge(entries[0], entries[1], entries[2])
+	data, err := marshalEvents([]Event{newSnapshot(result.Entries, time.Now())})
+	return data, result.Conflicts, err
+}
+
+// unionEvents appends the events both sides added after base in time order.
+// It fails if a side did not extend base or compacted since.
+func unionEvents(base, ours, theirs []Event) ([]Event, bool) {
+	var added []Event
+	seen := map[string]bool{}
+	for _, side := range [][]Event{ours, theirs} {
+		if len(side) < len(base) {
+			return nil, false
+		}
+		for i, ev := range base {
+			if side[i].ID != ev.ID {
+				return nil, false
+			}
+		}
+		for _, ev := range side[len(base):] {
+			if ev.Op == eventSnapshot {
+				return nil, false
+			}
+			if !seen[ev.ID] {
+				seen[ev.ID] = true
+				added = append(added, ev)
+			}
+		}
+	}
+	sort.SliceStable(added, func(i, j int) bool {
+		return added[i].At.Before(added[j].At)
+	})
+	return append(slices.Clone(base), added...), true
+}
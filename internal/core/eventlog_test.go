@@ -0,0 +1,201 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func eventLogPath(t *testing.T) string {
+	return filepath.Join(t.TempDir(), dataFileName+eventLogExt)
+}
+
+func TestEventLogAppendsAndReplays(t *testing.T) {
+	path := eventLogPath(t)
+
+	entries := AddEntry([]Entry{}, "One", "User", TypeTodo)
+	entries = AddEntry(entries, "Two", "User", TypeTodo)
+	entries = AddEntry(entries, "Three", "User", TypeNote)
+	if err := SaveEntries(path, entries); err != nil {
+		t.Fatal(err)
+	}
+	first, _ := os.ReadFile(path)
+
+	entries = MarkDone(entries, entries[0].ID, "User")
+	entries = EditEntry(entries, entries[1].ID, "Two edited", "User")
+	entries = SetPriority(entries, entries[1].ID, PriorityHigh)
+	entries = SwapEntries(entries, entries[0].ID, entries[2].ID)
+	if err := SaveEntries(path, entries); err != nil {
+		t.Fatal(err)
+	}
+	entries = RemoveEntry(entries, entries[2].ID)
+	if err := SaveEntries(path, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !bytes.HasPrefix(data, first) {
+		t.Error("Expected earlier events to be left untouched")
+	}
+	for _, op := range []string{`"op":"add"`, `"op":"done"`, `"op":"edit"`, `"op":"move"`, `"op":"remove"`} {
+		if !bytes.Contains(data, []byte(op)) {
+			t.Errorf("Expected a %s event in the log", op)
+		}
+	}
+
+	loaded, err := LoadEntries(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameEntries(loaded, entries) {
+		t.Errorf("Replay differs from the saved list:\n%v\n%v", loaded, entries)
+	}
+
+	// Saving the same list again appends nothing
+	if err := SaveEntries(path, loaded); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := os.ReadFile(path); !bytes.Equal(again, data) {
+		t.Error("Expected no events for an unchanged list")
+	}
+}
+
+func TestEventLogTornLine(t *testing.T) {
+	path := eventLogPath(t)
+	entries := AddEntry([]Entry{}, "One", "User", TypeTodo)
+	if err := SaveEntries(path, entries); err != nil {
+		t.Fatal(err)
+	}
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"id":"x","op":"ad`)
+	f.Close()
+
+	loaded, err := LoadEntries(path)
+	if err != nil || len(loaded) != 1 {
+		t.Fatalf("Expected the torn line to be ignored, got %v, %v", loaded, err)
+	}
+	entries = AddEntry(entries, "Two", "User", TypeTodo)
+	if err := SaveEntries(path, entries); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err = LoadEntries(path)
+	if err != nil || len(loaded) != 2 {
+		t.Errorf("Expected the next append to replace the torn line, got %v, %v", loaded, err)
+	}
+}
+
+func TestEventLogCorrupt(t *testing.T) {
+	path := eventLogPath(t)
+	if err := os.WriteFile(path, []byte("{\"id\":\"a\",\"op\":\"add\"}\n{garbage\n{\"id\":\"b\",\"op\":\"remove\"}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadEntries(path); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Expected ErrCorrupt, got %v", err)
+	}
+}
+
+func TestEventLogCompaction(t *testing.T) {
+	defer func(n int) { CompactThreshold = n }(CompactThreshold)
+	CompactThreshold = 3
+	path := eventLogPath(t)
+
+	var entries []Entry
+	for _, text := range []string{"One", "Two", "Three"} {
+		entries = AddEntry(entries, text, "User", TypeTodo)
+		if err := SaveEntries(path, entries); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries = MarkDone(entries, entries[1].ID, "User")
+	if err := SaveEntries(path, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if lines := strings.Count(string(data), "\n"); lines != 1 || !bytes.Contains(data, []byte(`"op":"snapshot"`)) {
+		t.Errorf("Expected a single snapshot line, got %s", data)
+	}
+	loaded, err := LoadEntries(path)
+	if err != nil || !sameEntries(loaded, entries) {
+		t.Errorf("Snapshot differs from the saved list: %v, %v", loaded, err)
+	}
+	backup, _ := os.ReadFile(BackupPath(path))
+	if strings.Count(string(backup), "\n") != 3 {
+		t.Errorf("Expected the backup to keep the uncompacted log, got %s", backup)
+	}
+}
+
+func TestEventLogConvertsYAML(t *testing.T) {
+	path := eventLogPath(t)
+	entries := AddEntry([]Entry{}, "One", "User", TypeTodo)
+	if err := WriteEntries(filepath.Join(filepath.Dir(path), dataFileName), entries); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(filepath.Dir(path), dataFileName), path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadEntries(path)
+	if err != nil || len(loaded) != 1 {
+		t.Fatalf("Expected a renamed YAML file to load, got %v, %v", loaded, err)
+	}
+	if err := SaveEntries(path, MarkDone(loaded, loaded[0].ID, "User")); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if !IsEventLog(data) {
+		t.Errorf("Expected the first save to rewrite the file as an event log, got %s", data)
+	}
+}
+
+func TestMergeEventLogs(t *testing.T) {
+	path := eventLogPath(t)
+	base := AddEntry([]Entry{}, "Task", "User", TypeTodo)
+	if err := SaveEntries(path, base); err != nil {
+		t.Fatal(err)
+	}
+	baseLog, _ := os.ReadFile(path)
+	id := base[0].ID
+
+	ours := AddEntry(EditEntry(append([]Entry(nil), base...), id, "Task edited", "bob"), "Ours", "User", TypeNote)
+	if err := SaveEntries(path, ours); err != nil {
+		t.Fatal(err)
+	}
+	oursLog, _ := os.ReadFile(path)
+
+	if err := os.WriteFile(path, baseLog, 0644); err != nil {
+		t.Fatal(err)
+	}
+	theirs := AddEntry(MarkDone(append([]Entry(nil), base...), id, "carol"), "Theirs", "User", TypeNote)
+	if err := SaveEntries(path, theirs); err != nil {
+		t.Fatal(err)
+	}
+	theirsLog, _ := os.ReadFile(path)
+
+	merged, conflicts, err := MergeEventLogs(baseLog, oursLog, theirsLog)
+	if err != nil || len(conflicts) != 0 {
+		t.Fatalf("Unexpected merge result: %v, %v", conflicts, err)
+	}
+	if !bytes.HasPrefix(merged, baseLog) {
+		t.Error("Expected the merged log to extend base")
+	}
+	entries, _, err := replayEvents(merged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || entries[0].Text != "Task edited" || entries[0].CompletedAt == nil {
+		t.Errorf("Expected both sides' changes, got %v", entries)
+	}
+	// Each side recorded a revision; the audit trail keeps both
+	var by []string
+	for _, r := range entries[0].Revisions {
+		by = append(by, r.By)
+	}
+	if slices.Sort(by); !slices.Equal(by, []string{"bob", "carol"}) {
+		t.Errorf("Expected the revisions of both sides, got %v", entries[0].Revisions)
+	}
+}
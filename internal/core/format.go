@@ -0,0 +1,69 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaVersion is the version of the machine-readable entry format.
+// Bump it whenever a field is renamed, removed or changes meaning.
+//
+//	1: initial format
+//	2: author replaced by created_by and assignees
+const SchemaVersion = 2
+
+type OutputFormat string
+
+const (
+	FormatText   OutputFormat = "text"
+	FormatJSON   OutputFormat = "json"
+	FormatYAML   OutputFormat = "yaml"
+	FormatNDJSON OutputFormat = "ndjson"
+)
+
+// EntryList is the envelope emitted by the json and yaml formats
+type EntryList struct {
+	SchemaVersion int     `yaml:"schema_version" json:"schema_version"`
+	Entries       []Entry `yaml:"entries" json:"entries"`
+}
+
+// ParseFormat validates a user supplied output format name
+func ParseFormat(s string) (OutputFormat, error) {
+	switch f := OutputFormat(s); f {
+	case FormatText, FormatJSON, FormatYAML, FormatNDJSON:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown format %q (want text, json, yaml or ndjson)", s)
+}
+
+// EncodeEntries writes entries in one of the machine-readable formats.
+// json and yaml wrap the entries in an EntryList, ndjson emits one entry per line.
+func EncodeEntries(w io.Writer, entries []Entry, format OutputFormat) error {
+	if entries == nil {
+		entries = []Entry{}
+	}
+	list := EntryList{SchemaVersion: SchemaVersion, Entries: entries}
+
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(list)
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		defer enc.Close()
+		return enc.Encode(list)
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("format %q is not machine-readable", format)
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseFormat(t *testing.T) {
+	for _, name := range []string{"text", "json", "yaml", "ndjson"} {
+		if _, err := ParseFormat(name); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", name, err)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
+
+func TestEncodeEntriesJSON(t *testing.T) {
+	entries := []Entry{}
+	entries = AddEntry(entries, "Note 1", "User", TypeNote)
+	entries = AddEntry(entries, "Task 1", "User", TypeTodo)
+	entries = MarkDone(entries, entries[1].ID, "User")
+
+	var buf bytes.Buffer
+	if err := EncodeEntries(&buf, entries, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw["schema_version"] != float64(SchemaVersion) {
+		t.Errorf("Expected schema_version %d, got %v", SchemaVersion, raw["schema_version"])
+	}
+
+	first := raw["entries"].([]any)[0].(map[string]any)
+	for _, key := range []string{"id", "created_at", "text", "created_by", "type"} {
+		if _, ok := first[key]; !ok {
+			t.Errorf("Expected field %q in JSON output", key)
+		}
+	}
+	if _, ok := first["completed_at"]; ok {
+		t.Error("Expected completed_at to be omitted for notes")
+	}
+
+	var list EntryList
+	if err := json.Unmarshal(buf.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Entries) != 2 || list.Entries[1].CompletedAt == nil {
+		t.Error("JSON output did not round-trip")
+	}
+}
+
+func TestEncodeEntriesEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := EncodeEntries(&buf, nil, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"entries": []`) {
+		t.Errorf("Expected empty array, got %s", buf.String())
+	}
+}
+
+func TestEncodeEntriesNDJSON(t *testing.T) {
+	entries := []Entry{}
+	entries = AddEntry(entries, "Note 1", "User", TypeNote)
+	entries = AddEntry(entries, "Note 2", "User", TypeNote)
+
+	var buf bytes.Buffer
+	if err := EncodeEntries(&buf, entries, FormatNDJSON); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
+	}
+	var e Entry
+	if err := json.Unmarshal([]byte(lines[1]), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Text != "Note 2" {
+		t.Errorf("Expected 'Note 2', got '%s'", e.Text)
+	}
+}
+
+func TestEncodeEntriesYAML(t *testing.T) {
+	entries := AddEntry([]Entry{}, "Note 1", "User", TypeNote)
+
+	var buf bytes.Buffer
+	if err := EncodeEntries(&buf, entries, FormatYAML); err != nil {
+		t.Fatal(err)
+	}
+	var list EntryList
+	if err := yaml.Unmarshal(buf.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if list.SchemaVersion != SchemaVersion || len(list.Entries) != 1 {
+		t.Errorf("Unexpected YAML output: %s", buf.String())
+	}
+}
+
+func TestEncodeEntriesText(t *testing.T) {
+	if err := EncodeEntries(&bytes.Buffer{}, nil, FormatText); err == nil {
+		t.Error("Expected error for text format")
+	}
+}
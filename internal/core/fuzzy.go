@@ -0,0 +1,109 @@
+package core
+
+import (
+	"sort"
+	"unicode"
+)
+
+// Fuzzy match scoring
+const (
+	fuzzyMatchScore       = 1
+	fuzzyConsecutiveBonus = 5
+	fuzzyWordStartBonus   = 8
+)
+
+// FuzzyMatch is an entry matched by FuzzyFind
+type FuzzyMatch struct {
+	Entry     Entry
+	Score     int
+	Field     string // YAML name of the field that matched best
+	Value     string // The matched value, e.g. one of several tags
+	Positions []int  // Rune offsets of the matched characters in Value
+}
+
+// FuzzyScore matches pattern against s as a case-insensitive subsequence.
+// Consecutive characters and characters at the start of a word score higher.
+// It returns the rune offsets of the matched characters.
+func FuzzyScore(pattern, s string) (int, []int, bool) {
+	p := []rune(toLowerString(pattern))
+	r := []rune(toLowerString(s))
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+
+	best, found := -1, false
+	var bestPositions []int
+	// Try each occurrence of the first character, so "rel" prefers the
+	// start of "release" over scattered letters earlier in the string
+	for start := range r {
+		if r[start] != p[0] {
+			continue
+		}
+		score, positions, ok := fuzzyFrom(p, r, start)
+		if ok && score > best {
+			best, bestPositions, found = score, positions, true
+		}
+	}
+	if !found {
+		return 0, nil, false
+	}
+	return best, bestPositions, true
+}
+
+func fuzzyFrom(p, r []rune, start int) (int, []int, bool) {
+	positions := make([]int, 0, len(p))
+	score := 0
+	pi := 0
+	for i := start; i < len(r) && pi < len(p); i++ {
+		if r[i] != p[pi] {
+			continue
+		}
+		score += fuzzyMatchScore
+		if len(positions) > 0 && positions[len(positions)-1] == i-1 {
+			score += fuzzyConsecutiveBonus
+		}
+		if i == 0 || !unicode.IsLetter(r[i-1]) && !unicode.IsDigit(r[i-1]) {
+			score += fuzzyWordStartBonus
+		}
+		positions = append(positions, i)
+		pi++
+	}
+	return score, positions, pi == len(p)
+}
+
+func toLowerString(s string) string {
+	r := []rune(s)
+	for i, c := range r {
+		r[i] = unicode.ToLower(c)
+	}
+	return string(r)
+}
+
+// FuzzyFind returns the entries whose text, creator, assignees or tags fuzzily
+// match pattern, best first. Equal scores keep the order of entries.
+func FuzzyFind(entries []Entry, pattern string) []FuzzyMatch {
+	var matches []FuzzyMatch
+	for _, e := range entries {
+		match := FuzzyMatch{Entry: e, Score: -1}
+		try := func(field, value string) {
+			if score, positions, ok := FuzzyScore(pattern, value); ok && score > match.Score {
+				match.Score, match.Field, match.Value, match.Positions = score, field, value, positions
+			}
+		}
+		try("text", e.Text)
+		try("created_by", e.CreatedBy)
+		for _, a := range e.Assignees {
+			try("assignees", a)
+		}
+		for _, t := range e.Tags {
+			try("tags", t)
+		}
+		if match.Score >= 0 {
+			matches = append(matches, match)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches
+}
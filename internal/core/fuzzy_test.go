@@ -0,0 +1,55 @@
+package core
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	score, positions, ok := FuzzyScore("rls", "Write release notes")
+	if !ok {
+		t.Fatal("Expected a subsequence match")
+	}
+	if !slices.Equal(positions, []int{6, 8, 11}) {
+		t.Errorf("Unexpected positions %v", positions)
+	}
+
+	if _, _, ok := FuzzyScore("xyz", "Write release notes"); ok {
+		t.Error("Expected no match")
+	}
+
+	// A run of consecutive characters at a word start beats scattered ones
+	tight, _, _ := FuzzyScore("rel", "Write release notes")
+	loose, _, _ := FuzzyScore("rel", "rebuild the wheel")
+	if tight <= loose {
+		t.Errorf("Expected %d > %d", tight, loose)
+	}
+	if score <= 0 {
+		t.Errorf("Expected a positive score, got %d", score)
+	}
+}
+
+func TestFuzzyFind(t *testing.T) {
+	entries := []Entry{}
+	entries = AddEntry(entries, "Fix login flow", "alice", TypeTodo)
+	entries = AddEntry(entries, "Update docs #frontend", "bob", TypeNote)
+	entries = AddEntry(entries, "Ship @frank", "carol", TypeTodo)
+
+	matches := FuzzyFind(entries, "fr")
+	if len(matches) != 2 {
+		t.Fatalf("Expected 2 matches, got %d", len(matches))
+	}
+	if matches[0].Field != "tags" || matches[0].Value != "frontend" {
+		t.Errorf("Expected the tag match first, got %s %q", matches[0].Field, matches[0].Value)
+	}
+	if matches[1].Field != "assignees" {
+		t.Errorf("Expected an assignee match, got %s", matches[1].Field)
+	}
+
+	if got := FuzzyFind(entries, "alc"); len(got) != 1 || got[0].Field != "created_by" {
+		t.Errorf("Expected a creator match, got %+v", got)
+	}
+	if got := FuzzyFind(entries, ""); len(got) != 3 {
+		t.Errorf("Expected an empty pattern to match everything, got %d", len(got))
+	}
+}
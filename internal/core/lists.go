@@ -0,0 +1,96 @@
+package core
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultList holds the entries that were not put on a named list. Their
+// List field stays empty, so files from before lists existed are unchanged.
+const DefaultList = "main"
+
+// ListOf returns the name of the list an entry is on
+func ListOf(e Entry) string {
+	if e.List == "" {
+		return DefaultList
+	}
+	return e.List
+}
+
+// NormalizeList validates a list name and returns it in lowercase. Names
+// start with a letter and contain letters, digits, - and _.
+func NormalizeList(s string) (string, bool) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if name == "" {
+		return "", false
+	}
+	for i, r := range name {
+		if i == 0 && !unicode.IsLetter(r) {
+			return "", false
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return "", false
+		}
+	}
+	return name, true
+}
+
+// ListNames returns the lists that hold live entries: the default list
+// first, then the others alphabetically
+func ListNames(entries []Entry) []string {
+	seen := map[string]bool{DefaultList: true}
+	var names []string
+	for _, e := range LiveEntries(entries) {
+		if name := ListOf(e); !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultList}, names...)
+}
+
+// FilterByList returns the entries on a list, including trashed ones
+func FilterByList(entries []Entry, name string) []Entry {
+	var filtered []Entry
+	for _, e := range entries {
+		if ListOf(e) == name {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// MoveToList puts entries and their subtasks on another list
+func MoveToList(entries []Entry, ids map[string]struct{}, name string) []Entry {
+	if name == DefaultList {
+		name = ""
+	}
+	moved := withDescendants(entries, ids)
+	for i, e := range entries {
+		if _, ok := moved[e.ID]; ok {
+			entries[i].List = name
+		}
+	}
+	return entries
+}
+
+// withDescendants returns ids plus the IDs of all subtasks below them
+func withDescendants(entries []Entry, ids map[string]struct{}) map[string]struct{} {
+	all := make(map[string]struct{}, len(ids))
+	for id := range ids {
+		all[id] = struct{}{}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, e := range entries {
+			_, isChild := all[e.ParentID]
+			if _, ok := all[e.ID]; !ok && e.ParentID != "" && isChild {
+				all[e.ID] = struct{}{}
+				changed = true
+			}
+		}
+	}
+	return all
+}
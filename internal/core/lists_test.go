@@ -0,0 +1,70 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMoveToListTakesSubtasks(t *testing.T) {
+	entries := AddEntry([]Entry{}, "Parent", "User", TypeTodo)
+	entries = AddEntry(entries, "Child", "User", TypeTodo)
+	entries = AddEntry(entries, "Other", "User", TypeNote)
+	entries, _ = SetParent(entries, entries[1].ID, entries[0].ID)
+
+	entries = MoveToList(entries, map[string]struct{}{entries[0].ID: {}}, "ideas")
+	if ListOf(entries[0]) != "ideas" || ListOf(entries[1]) != "ideas" || ListOf(entries[2]) != DefaultList {
+		t.Errorf("Expected the parent and its subtask to move, got %v", entries)
+	}
+	if names := ListNames(entries); len(names) != 2 || names[0] != DefaultList || names[1] != "ideas" {
+		t.Errorf("Unexpected lists %v", names)
+	}
+
+	// New subtasks join their parent's list
+	entries = AddEntry(entries, "Grandchild", "User", TypeTodo)
+	entries, _ = SetParent(entries, entries[3].ID, entries[1].ID)
+	if ListOf(entries[3]) != "ideas" {
+		t.Errorf("Expected the subtask on its parent's list, got %q", ListOf(entries[3]))
+	}
+
+	// Moving back to the default list clears the field
+	entries = MoveToList(entries, map[string]struct{}{entries[0].ID: {}}, DefaultList)
+	if entries[0].List != "" || entries[3].List != "" {
+		t.Errorf("Expected an empty list field for the default list, got %v", entries)
+	}
+}
+
+func TestNormalizeList(t *testing.T) {
+	for in, want := range map[string]string{"Ideas": "ideas", " sprint-42 ": "sprint-42", "2026": "", "a b": "", "": ""} {
+		got, ok := NormalizeList(in)
+		if got != want || ok != (want != "") {
+			t.Errorf("NormalizeList(%q) = %q, %v; want %q", in, got, ok, want)
+		}
+	}
+}
+
+func TestListsExport(t *testing.T) {
+	entries := AddEntry([]Entry{}, "Sprint task", "User", TypeTodo)
+	if export := GenerateListsExportMarkdown(entries); export != GenerateExportMarkdown(entries) {
+		t.Errorf("Expected a single list to export as before, got:\n%s", export)
+	}
+
+	entries = AddEntry(entries, "Meeting notes", "User", TypeNote)
+	entries = MoveToList(entries, map[string]struct{}{entries[1].ID: {}}, "meetings")
+	export := GenerateListsExportMarkdown(entries)
+	for _, want := range []string{"# List: main\n\n## Context", "# List: meetings\n\n## Context", "Meeting notes"} {
+		if !strings.Contains(export, want) {
+			t.Errorf("Expected %q in the export:\n%s", want, export)
+		}
+	}
+	if strings.Index(export, "Meeting notes") < strings.Index(export, "# List: meetings") {
+		t.Errorf("Expected the note under its own list:\n%s", export)
+	}
+
+	q, err := ParseQuery("list:meetings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matched := q.Filter(entries, QueryContext{}); len(matched) != 1 || matched[0].Text != "Meeting notes" {
+		t.Errorf("Expected list:meetings to match the note, got %v", matched)
+	}
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+const lockSuffix = ".lock"
+
+// LockTimeout bounds how long WithLock waits for another writer
+var LockTimeout = 10 * time.Second
+
+var ErrLockTimeout = errors.New("timed out waiting for the data file lock")
+
+// lockMu serializes writers within this process, where advisory locks are
+// not guaranteed to conflict on every platform
+var lockMu sync.Mutex
+
+// LockPath returns the lock file guarding a data file. A separate file is used
+// because SaveEntries replaces the data file, which would drop a lock held on it.
+func LockPath(path string) string {
+	return path + lockSuffix
+}
+
+// WithLock runs fn while holding an exclusive advisory lock on the data file
+func WithLock(path string, fn func() error) error {
+	lockMu.Lock()
+	defer lockMu.Unlock()
+
+	f, err := os.OpenFile(LockPath(path), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	deadline := time.Now().Add(LockTimeout)
+	for {
+		ok, err := tryLockFile(f)
+		if err != nil {
+			return err
+		}
+		if ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			return ErrLockTimeout
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	defer unlockFile(f)
+
+	return fn()
+}
+
+// UpdateEntries loads the data file, applies fn, saves the result and records
+// the change for undo, all under the file lock so concurrent writers cannot
+// interleave. If only the undo history fails, the error wraps ErrHistory.
+func UpdateEntries(path string, fn func([]Entry) ([]Entry, error)) error {
+	return WithLock(path, func() error {
+		entries, err := LoadEntries(path)
+		if err != nil {
+			return err
+		}
+		// fn may modify entries in place, so keep a copy for the undo history
+		before := slices.Clone(entries)
+		entries, err = fn(entries)
+		if err != nil {
+			return err
+		}
+		if err := SaveEntries(path, entries); err != nil {
+			return err
+		}
+		return recordHistory(path, before, entries)
+	})
+}
+
+// SyncEntries saves ours on top of whatever is currently on disk. base is the
+// version ours was derived from; changes made by other writers since then are
+// kept via a three-way merge. The change from base to ours is recorded for
+// undo under the same lock. The merged entries that were written are returned.
+func SyncEntries(path string, base, ours []Entry) ([]Entry, error) {
+	var merged []Entry
+	err := WithLock(path, func() error {
+		theirs, err := LoadEntries(path)
+		if err != nil {
+			return err
+		}
+		merged = MergeEntries(base, ours, theirs)
+		if err := SaveEntries(path, merged); err != nil {
+			return err
+		}
+		return recordHistory(path, base, ours)
+	})
+	return merged, err
+}
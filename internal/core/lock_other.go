@@ -0,0 +1,16 @@
+//go:build !unix
+
+package core
+
+import "os"
+
+// Advisory locks are not implemented on this platform. Writers in the same
+// process are still serialized by lockMu.
+
+func tryLockFile(f *os.File) (bool, error) {
+	return true, nil
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestUpdateEntriesConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), dataFileName)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := UpdateEntries(path, func(entries []Entry) ([]Entry, error) {
+				return AddEntry(entries, "Note", "User", TypeNote), nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	entries, err := LoadEntries(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 10 {
+		t.Errorf("Expected 10 entries, got %d", len(entries))
+	}
+}
+
+func TestSyncEntriesKeepsOtherWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), dataFileName)
+	if err := SaveEntries(path, AddEntry([]Entry{}, "Task", "User", TypeTodo)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Two writers load the same version
+	base, err := LoadEntries(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := base[0].ID
+
+	// Another process completes the task and adds a note
+	err = UpdateEntries(path, func(entries []Entry) ([]Entry, error) {
+		entries = MarkDone(entries, id, "User")
+		return AddEntry(entries, "Theirs", "Other", TypeNote), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// We add a note based on the stale version
+	ours := AddEntry(append([]Entry{}, base...), "Ours", "User", TypeNote)
+	merged, err := SyncEntries(path, base, ours)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(merged))
+	}
+
+	onDisk, err := LoadEntries(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(onDisk) != 3 {
+		t.Errorf("Expected 3 entries on disk, got %d", len(onDisk))
+	}
+	if e, _ := FindEntry(onDisk, id); e.CompletedAt == nil {
+		t.Error("Expected the other writer's completion to survive")
+	}
+}
@@ -2,6 +2,8 @@ package core
 
 import (
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -10,51 +12,161 @@ import (
 
 // AddEntry creates a new entry and appends it to the list
 func AddEntry(entries []Entry, text string, author string, entryType EntryType) []Entry {
-	// Parse for @mention in text if it's a todo to override author
-	finalAuthor := author
+	// Parse inline tokens: #tag labels and @mention assigns any entry. In todos,
+	// !priority sets the priority and due:<when> sets the due date.
+	now := time.Now()
 	finalText := text
+	priority := PriorityNone
+	var dueAt *time.Time
+	var tags, assignees []string
 
-	if entryType == TypeTodo && strings.Contains(text, "@") {
+	hasTodoTokens := entryType == TypeTodo && strings.ContainsAny(text, "!:")
+	if hasTodoTokens || strings.ContainsAny(text, "#@") {
 		parts := strings.Fields(text)
 		var newParts []string
 		for _, p := range parts {
-			if strings.HasPrefix(p, "@") && len(p) > 1 {
-				finalAuthor = p[1:] // Remove @
-				// Do not add the mention to newParts, effectively removing it from the text
-			} else {
-				newParts = append(newParts, p)
+			if tag, ok := parseTagToken(p); ok {
+				tags = addTag(tags, tag)
+				continue
 			}
+			if name, ok := parseMentionToken(p); ok {
+				assignees = addAssignee(assignees, name)
+				if entryType == TypeTodo {
+					// Do not add the mention to newParts, effectively removing it from the text.
+					// Notes keep it, since it usually reads as part of the sentence.
+					continue
+				}
+			}
+			if entryType == TypeTodo {
+				if level, ok := parsePriorityToken(p); ok {
+					priority = level
+					continue
+				} else if due, ok := parseDueToken(p, now); ok {
+					dueAt = &due
+					continue
+				}
+			}
+			newParts = append(newParts, p)
 		}
 		finalText = strings.Join(newParts, " ")
 	}
 
 	newEntry := Entry{
 		ID:        uuid.New().String(),
-		CreatedAt: time.Now(),
+		CreatedAt: now,
 		Text:      finalText,
-		Author:    finalAuthor,
+		CreatedBy: author,
+		Assignees: assignees,
 		Type:      entryType,
+		Priority:  priority,
+		DueAt:     dueAt,
+		Tags:      tags,
 	}
 	return append(entries, newEntry)
 }
 
-// MarkDone sets the completed_at timestamp for a specific entry ID
-func MarkDone(entries []Entry, id string) []Entry {
+// ParsePriority maps a priority name or alias (urgent/u/p0, high/h/p1,
+// medium/med/m/p2, low/l/p3, none) to a Priority
+func ParsePriority(s string) (Priority, bool) {
+	switch strings.ToLower(s) {
+	case "urgent", "u", "p0":
+		return PriorityUrgent, true
+	case "high", "h", "p1":
+		return PriorityHigh, true
+	case "medium", "med", "m", "p2":
+		return PriorityMedium, true
+	case "low", "l", "p3":
+		return PriorityLow, true
+	case "none", "":
+		return PriorityNone, true
+	}
+	return PriorityNone, false
+}
+
+// parsePriorityToken recognizes inline priority tokens like !high. The token
+// must be a whole word, so text like "wow!h" is not a priority.
+func parsePriorityToken(token string) (Priority, bool) {
+	if !strings.HasPrefix(token, "!") || len(token) < 2 {
+		return PriorityNone, false
+	}
+	return ParsePriority(token[1:])
+}
+
+// Rank orders priorities from most (0) to least important
+func (p Priority) Rank() int {
+	switch p {
+	case PriorityUrgent:
+		return 0
+	case PriorityHigh:
+		return 1
+	case PriorityMedium:
+		return 2
+	case PriorityLow:
+		return 3
+	}
+	return 4
+}
+
+// SetPriority changes the priority of an entry
+func SetPriority(entries []Entry, id string, priority Priority) []Entry {
+	for i, e := range entries {
+		if e.ID == id {
+			entries[i].Priority = priority
+			return entries
+		}
+	}
+	return entries
+}
+
+// SortByPriority returns a copy of entries ordered by priority.
+// The sort is stable, so entries of equal priority keep their order.
+func SortByPriority(entries []Entry) []Entry {
+	sorted := append([]Entry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority.Rank() < sorted[j].Priority.Rank()
+	})
+	return sorted
+}
+
+// FindEntry returns the entry with the given ID
+func FindEntry(entries []Entry, id string) (Entry, bool) {
+	for _, e := range entries {
+		if e.ID == id {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// MarkDone sets the completed_at timestamp for a specific entry ID.
+// The status is reset, so the todo is in the workflow's default done state.
+// It does not check the workflow's transitions; user actions go through
+// CompleteTodo instead.
+func MarkDone(entries []Entry, id string, author string) []Entry {
 	for i, e := range entries {
 		if e.ID == id {
+			if e.CompletedAt == nil {
+				addRevision(&entries[i], Revision{By: author, Action: RevisionDone})
+			}
 			now := time.Now()
 			entries[i].CompletedAt = &now
+			entries[i].Status = ""
 			return entries
 		}
 	}
 	return entries
 }
 
-// MarkUndone removes the completed_at timestamp
-func MarkUndone(entries []Entry, id string) []Entry {
+// MarkUndone removes the completed_at timestamp and puts the todo back in the
+// initial state. Like MarkDone, it does not check the workflow; see ReopenTodo.
+func MarkUndone(entries []Entry, id string, author string) []Entry {
 	for i, e := range entries {
 		if e.ID == id {
+			if e.CompletedAt != nil {
+				addRevision(&entries[i], Revision{By: author, Action: RevisionUndone})
+			}
 			entries[i].CompletedAt = nil
+			entries[i].Status = ""
 			return entries
 		}
 	}
@@ -83,17 +195,40 @@ func RemoveEntries(entries []Entry, ids map[string]struct{}) []Entry {
 	return newEntries
 }
 
-// EditEntry updates the text of an entry
-func EditEntry(entries []Entry, id string, newText string) []Entry {
+// EditEntry updates the text of an entry, recording the previous text as a
+// revision. The #tag tokens in newText replace the entry's tags.
+func EditEntry(entries []Entry, id string, newText string, author string) []Entry {
 	for i, e := range entries {
 		if e.ID == id {
-			entries[i].Text = newText
+			text, tags := splitTags(newText)
+			if e.Text != text || !slices.Equal(e.Tags, tags) {
+				addRevision(&entries[i], Revision{By: author, Action: RevisionEdit, Text: TextWithTags(e)})
+			}
+			entries[i].Text = text
+			entries[i].Tags = tags
 			return entries
 		}
 	}
 	return entries
 }
 
+// SwapEntries exchanges the positions of two entries, e.g. to reorder cards on the board
+func SwapEntries(entries []Entry, a, b string) []Entry {
+	i, j := -1, -1
+	for k, e := range entries {
+		switch e.ID {
+		case a:
+			i = k
+		case b:
+			j = k
+		}
+	}
+	if i >= 0 && j >= 0 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries
+}
+
 // FilterEntries returns a subset of entries based on criteria
 // This is a helper for view logic, not necessarily changing state
 func FilterEntries(entries []Entry, filter string) []Entry {
@@ -103,17 +238,20 @@ func FilterEntries(entries []Entry, filter string) []Entry {
 	var filtered []Entry
 	for _, e := range entries {
 		if strings.Contains(strings.ToLower(e.Text), strings.ToLower(filter)) ||
-			strings.Contains(strings.ToLower(e.Author), strings.ToLower(filter)) {
+			strings.Contains(strings.ToLower(e.CreatedBy), strings.ToLower(filter)) ||
+			strings.Contains(strings.ToLower(strings.Join(e.Assignees, " ")), strings.ToLower(filter)) ||
+			strings.Contains(strings.Join(e.Tags, " "), strings.ToLower(filter)) {
 			filtered = append(filtered, e)
 		}
 	}
 	return filtered
 }
 
-// GetActiveTodos returns entries that are TODO and not completed
+// GetActiveTodos returns entries that are TODO and not completed.
+// Like the other selectors, it leaves out entries in the trash.
 func GetActiveTodos(entries []Entry) []Entry {
 	var active []Entry
-	for _, e := range entries {
+	for _, e := range LiveEntries(entries) {
 		if e.Type == TypeTodo && e.CompletedAt == nil {
 			active = append(active, e)
 		}
@@ -124,7 +262,7 @@ func GetActiveTodos(entries []Entry) []Entry {
 // GetCompletedTodos returns entries that are TODO and completed
 func GetCompletedTodos(entries []Entry) []Entry {
 	var completed []Entry
-	for _, e := range entries {
+	for _, e := range LiveEntries(entries) {
 		if e.Type == TypeTodo && e.CompletedAt != nil {
 			completed = append(completed, e)
 		}
@@ -138,7 +276,7 @@ func GetCompletedTodos(entries []Entry) []Entry {
 // Usually notes don't have a completed state, so they are always active.
 func GetActiveItems(entries []Entry) []Entry {
 	var active []Entry
-	for _, e := range entries {
+	for _, e := range LiveEntries(entries) {
 		if e.Type == TypeNote || (e.Type == TypeTodo && e.CompletedAt == nil) {
 			active = append(active, e)
 		}
@@ -149,6 +287,30 @@ func GetActiveItems(entries []Entry) []Entry {
 // GenerateExportMarkdown creates the markdown content for export
 func GenerateExportMarkdown(entries []Entry) string {
 	var sb strings.Builder
+	writeExport(&sb, entries, "#")
+	return sb.String()
+}
+
+// GenerateListsExportMarkdown exports every list under its own heading. With
+// only the default list it is the same as GenerateExportMarkdown.
+func GenerateListsExportMarkdown(entries []Entry) string {
+	lists := ListNames(entries)
+	if len(lists) == 1 {
+		return GenerateExportMarkdown(entries)
+	}
+	var sb strings.Builder
+	for i, name := range lists {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("# List: " + name + "\n\n")
+		writeExport(&sb, FilterByList(entries, name), "##")
+	}
+	return sb.String()
+}
+
+// writeExport writes the notes and tasks sections with headings of the given level
+func writeExport(sb *strings.Builder, entries []Entry, heading string) {
 
 	// Sort entries by CreatedAt (assuming they are already appended in order, but let's be safe if we merge lists later)
 	// For now, assuming input is time-ordered or we just iterate.
@@ -159,7 +321,7 @@ func GenerateExportMarkdown(entries []Entry) string {
 	var notes []Entry
 	var todos []Entry
 
-	for _, e := range entries {
+	for _, e := range SortByPriority(LiveEntries(entries)) {
 		if e.Type == TypeNote {
 			notes = append(notes, e)
 		} else if e.Type == TypeTodo {
@@ -172,27 +334,47 @@ func GenerateExportMarkdown(entries []Entry) string {
 		return t.Format("2006-01-02 15:04")
 	}
 
-	sb.WriteString("# Context\n\n")
+	sb.WriteString(heading + " Context\n\n")
 	if len(notes) == 0 {
 		sb.WriteString("_No notes._\n")
 	} else {
 		for _, n := range notes {
-			sb.WriteString(fmt.Sprintf("- **%s** (%s): %s\n", n.Author, fmtDate(n.CreatedAt), n.Text))
+			sb.WriteString(fmt.Sprintf("- **%s** (%s): %s%s\n", FormatPeople(n), fmtDate(n.CreatedAt), n.Text, exportTags(n.Tags)))
 		}
 	}
 
-	sb.WriteString("\n# Tasks\n\n")
+	sb.WriteString("\n" + heading + " Tasks\n\n")
 	if len(todos) == 0 {
 		sb.WriteString("_No tasks._\n")
 	} else {
-		for _, t := range todos {
+		// Subtasks are nested below their parent task
+		for _, item := range FlattenTree(todos, nil) {
+			t := item.Entry
+			indent := strings.Repeat("  ", item.Depth)
 			check := " "
 			if t.CompletedAt != nil {
 				check = "x"
 			}
-			sb.WriteString(fmt.Sprintf("- [%s] **%s** (%s): %s\n", check, t.Author, fmtDate(t.CreatedAt), t.Text))
+			meta := ""
+			if t.Priority != PriorityNone {
+				meta += " !" + string(t.Priority)
+			}
+			if t.DueAt != nil {
+				meta += " due " + FormatDue(*t.DueAt)
+			}
+			if t.Status != "" {
+				meta += " _" + t.Status + "_"
+			}
+			sb.WriteString(fmt.Sprintf("%s- [%s] **%s** (%s)%s: %s%s\n", indent, check, FormatPeople(t), fmtDate(t.CreatedAt), meta, t.Text, exportTags(t.Tags)))
 		}
 	}
+}
 
+// exportTags renders tags as inline code so Markdown does not turn them into headings or links
+func exportTags(tags []string) string {
+	var sb strings.Builder
+	for _, t := range tags {
+		sb.WriteString(" `#" + t + "`")
+	}
 	return sb.String()
 }
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"slices"
 	"strings"
 	"testing"
 )
@@ -21,8 +22,11 @@ func TestAddEntry(t *testing.T) {
 	if len(entries) != 2 {
 		t.Errorf("Expected 2 entries, got %d", len(entries))
 	}
-	if entries[1].Author != "Bob" {
-		t.Errorf("Expected author Bob, got %s", entries[1].Author)
+	if len(entries[1].Assignees) != 1 || entries[1].Assignees[0] != "Bob" {
+		t.Errorf("Expected assignee Bob, got %v", entries[1].Assignees)
+	}
+	if entries[1].CreatedBy != "User" {
+		t.Errorf("Expected creator User, got %s", entries[1].CreatedBy)
 	}
 	if strings.Contains(entries[1].Text, "@Bob") {
 		t.Error("Entry text should not contain the @mention")
@@ -32,12 +36,103 @@ func TestAddEntry(t *testing.T) {
 	}
 }
 
+func TestAddEntryPriority(t *testing.T) {
+	entries := []Entry{}
+	entries = AddEntry(entries, "Fix the build !high @Bob", "User", TypeTodo)
+
+	if entries[0].Priority != PriorityHigh {
+		t.Errorf("Expected priority high, got '%s'", entries[0].Priority)
+	}
+	if entries[0].Text != "Fix the build" {
+		t.Errorf("Expected 'Fix the build', got '%s'", entries[0].Text)
+	}
+
+	// Unknown levels and notes are left alone
+	entries = AddEntry(entries, "Wow !amazing", "User", TypeTodo)
+	if entries[1].Priority != PriorityNone || entries[1].Text != "Wow !amazing" {
+		t.Errorf("Expected unknown priority to stay in text, got '%s'", entries[1].Text)
+	}
+	// Markers only count as a token of their own, not inside a word
+	entries = AddEntry(entries, "wow!h really!!l", "User", TypeTodo)
+	if entries[2].Priority != PriorityNone || entries[2].Text != "wow!h really!!l" {
+		t.Errorf("Expected markers inside words to stay in text, got '%s'", entries[2].Text)
+	}
+	entries = AddEntry(entries, "Note !high", "User", TypeNote)
+	if entries[3].Priority != PriorityNone {
+		t.Error("Expected notes to have no priority")
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	cases := map[string]Priority{
+		"urgent": PriorityUrgent, "P0": PriorityUrgent,
+		"high": PriorityHigh, "P1": PriorityHigh,
+		"med": PriorityMedium, "p2": PriorityMedium,
+		"l": PriorityLow, "none": PriorityNone,
+	}
+	for in, want := range cases {
+		got, ok := ParsePriority(in)
+		if !ok || got != want {
+			t.Errorf("ParsePriority(%q) = %q, %v; want %q", in, got, ok, want)
+		}
+	}
+	if _, ok := ParsePriority("urgent-ish"); ok {
+		t.Error("Expected unknown priority to be rejected")
+	}
+}
+
+func TestSetPriority(t *testing.T) {
+	entries := AddEntry([]Entry{}, "Task 1", "User", TypeTodo)
+	entries = SetPriority(entries, entries[0].ID, PriorityLow)
+	if entries[0].Priority != PriorityLow {
+		t.Errorf("Expected priority low, got '%s'", entries[0].Priority)
+	}
+}
+
+func TestSortByPriority(t *testing.T) {
+	entries := []Entry{}
+	entries = AddEntry(entries, "Note", "User", TypeNote)
+	entries = AddEntry(entries, "Low !low", "User", TypeTodo)
+	entries = AddEntry(entries, "High !high", "User", TypeTodo)
+	entries = AddEntry(entries, "Other high !high", "User", TypeTodo)
+	entries = AddEntry(entries, "Outage !p0", "User", TypeTodo)
+
+	sorted := SortByPriority(entries)
+	want := []string{"Outage", "High", "Other high", "Low", "Note"}
+	for i, text := range want {
+		if sorted[i].Text != text {
+			t.Errorf("Position %d: expected '%s', got '%s'", i, text, sorted[i].Text)
+		}
+	}
+	if entries[0].Text != "Note" {
+		t.Error("SortByPriority should not modify its input")
+	}
+}
+
+func TestFindEntry(t *testing.T) {
+	entries := []Entry{}
+	entries = AddEntry(entries, "Task 1", "User", TypeTodo)
+	entries = AddEntry(entries, "Task 2", "User", TypeTodo)
+
+	e, ok := FindEntry(entries, entries[1].ID)
+	if !ok {
+		t.Fatal("Expected entry to be found")
+	}
+	if e.Text != "Task 2" {
+		t.Errorf("Expected 'Task 2', got '%s'", e.Text)
+	}
+
+	if _, ok := FindEntry(entries, "missing"); ok {
+		t.Error("Expected missing ID not to be found")
+	}
+}
+
 func TestMarkDone(t *testing.T) {
 	entries := []Entry{}
 	entries = AddEntry(entries, "Task 1", "User", TypeTodo)
 	id := entries[0].ID
 
-	entries = MarkDone(entries, id)
+	entries = MarkDone(entries, id, "User")
 	if entries[0].CompletedAt == nil {
 		t.Error("Expected CompletedAt to be set")
 	}
@@ -47,8 +142,8 @@ func TestMarkUndone(t *testing.T) {
 	entries := []Entry{}
 	entries = AddEntry(entries, "Task 1", "User", TypeTodo)
 	id := entries[0].ID
-	entries = MarkDone(entries, id)
-	entries = MarkUndone(entries, id)
+	entries = MarkDone(entries, id, "User")
+	entries = MarkUndone(entries, id, "User")
 
 	if entries[0].CompletedAt != nil {
 		t.Error("Expected CompletedAt to be nil after undone")
@@ -95,12 +190,46 @@ func TestEditEntry(t *testing.T) {
 	entries = AddEntry(entries, "Old Text", "User", TypeTodo)
 	id := entries[0].ID
 
-	entries = EditEntry(entries, id, "New Text")
+	entries = EditEntry(entries, id, "New Text", "User")
 	if entries[0].Text != "New Text" {
 		t.Errorf("Expected New Text, got %s", entries[0].Text)
 	}
 }
 
+func TestEditEntryTags(t *testing.T) {
+	entries := AddEntry([]Entry{}, "Fix login #backend #bug", "User", TypeTodo)
+	id := entries[0].ID
+	if got := TextWithTags(entries[0]); got != "Fix login #backend #bug" {
+		t.Errorf("Expected the tags appended to the text, got %q", got)
+	}
+
+	entries = EditEntry(entries, id, "Fix login #backend #auth", "User")
+	if entries[0].Text != "Fix login" || !slices.Equal(entries[0].Tags, []string{"backend", "auth"}) {
+		t.Errorf("Expected the edited tags, got %q %v", entries[0].Text, entries[0].Tags)
+	}
+	if revs := entries[0].Revisions; len(revs) != 1 || revs[0].Text != "Fix login #backend #bug" {
+		t.Errorf("Expected the previous text and tags as a revision, got %v", revs)
+	}
+
+	entries = EditEntry(entries, id, "Fix login", "User")
+	if len(entries[0].Tags) != 0 || len(entries[0].Revisions) != 2 {
+		t.Errorf("Expected removing the tags to clear them, got %v", entries[0].Tags)
+	}
+}
+
+func TestSwapEntries(t *testing.T) {
+	entries := []Entry{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+
+	entries = SwapEntries(entries, "a", "c")
+	if entries[0].ID != "c" || entries[1].ID != "b" || entries[2].ID != "a" {
+		t.Errorf("Unexpected order: %v", entries)
+	}
+	entries = SwapEntries(entries, "a", "missing")
+	if entries[2].ID != "a" {
+		t.Error("Expected no change when an ID is missing")
+	}
+}
+
 func TestFilterEntries(t *testing.T) {
 	entries := []Entry{}
 	entries = AddEntry(entries, "Apple", "User", TypeNote)
@@ -139,9 +268,27 @@ func TestGenerateExportMarkdown(t *testing.T) {
 	}
 
 	// Test completed task
-	entries = MarkDone(entries, entries[1].ID)
+	entries = MarkDone(entries, entries[1].ID, "User")
 	md = GenerateExportMarkdown(entries)
 	if !strings.Contains(md, "- [x]") { // Checked task
 		t.Error("Markdown missing checked task indicator")
 	}
+
+	entries = SetPriority(entries, entries[1].ID, PriorityHigh)
+	md = GenerateExportMarkdown(entries)
+	if !strings.Contains(md, "!high") {
+		t.Error("Markdown missing task priority")
+	}
+
+	entries = AddEntry(entries, "Task 2 due:2026-11-01", "User", TypeTodo)
+	md = GenerateExportMarkdown(entries)
+	if !strings.Contains(md, "due 2026-11-01") {
+		t.Error("Markdown missing due date")
+	}
+
+	entries = AddEntry(entries, "Note 2 #docs", "User", TypeNote)
+	md = GenerateExportMarkdown(entries)
+	if !strings.Contains(md, "`#docs`") {
+		t.Error("Markdown missing tags")
+	}
 }
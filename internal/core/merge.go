@@ -0,0 +1,247 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// MergeConflict records a field both sides changed to different values.
+// The merge keeps our value; conflicts are reported so callers can warn.
+type MergeConflict struct {
+	ID    string
+	Field string // YAML name of the field
+}
+
+// MergeResult is the outcome of a three-way merge
+type MergeResult struct {
+	Entries   []Entry
+	Conflicts []MergeConflict
+}
+
+// MergeEntries performs a three-way merge of two lists derived from base, keyed by ID.
+// See Merge for the rules; conflicts are resolved in favour of ours.
+func MergeEntries(base, ours, theirs []Entry) []Entry {
+	return Merge(base, ours, theirs).Entries
+}
+
+// Merge performs a three-way merge of two lists derived from base, keyed by ID.
+//
+//   - Entries added on either side are kept.
+//   - Entries changed on both sides are merged field by field, so one side
+//     completing a todo while the other edits its text keeps both changes.
+//   - When both sides changed the same field, a field resolver decides if one
+//     exists; otherwise ours wins and a MergeConflict is reported.
+//   - A deletion wins unless the other side changed the entry after base.
+//
+// The result follows the order of theirs, with entries only ours added appended.
+func Merge(base, ours, theirs []Entry) MergeResult {
+	// Fast paths keep ours verbatim (including its ordering) when nobody else wrote
+	if sameEntries(base, theirs) {
+		return MergeResult{Entries: ours}
+	}
+	if sameEntries(base, ours) {
+		return MergeResult{Entries: theirs}
+	}
+
+	baseByID := indexEntries(base)
+	oursByID := indexEntries(ours)
+	theirsByID := indexEntries(theirs)
+
+	var result MergeResult
+	result.Entries = make([]Entry, 0, len(theirs)+len(ours))
+	for _, t := range theirs {
+		b, inBase := baseByID[t.ID]
+		o, inOurs := oursByID[t.ID]
+		switch {
+		case inOurs:
+			// Present on both sides; an entry added on both is merged against an empty base
+			merged, fields := mergeEntry(b, o, t)
+			result.Entries = append(result.Entries, merged)
+			for _, f := range fields {
+				result.Conflicts = append(result.Conflicts, MergeConflict{ID: t.ID, Field: f})
+			}
+		case inBase && entriesEqual(b, t):
+			// We deleted it and they did not touch it
+		default:
+			// They added it, or changed it after we deleted it
+			result.Entries = append(result.Entries, t)
+		}
+	}
+	for _, o := range ours {
+		if _, inTheirs := theirsByID[o.ID]; inTheirs {
+			continue
+		}
+		if b, inBase := baseByID[o.ID]; inBase && entriesEqual(b, o) {
+			// They deleted it and we did not touch it
+			continue
+		}
+		result.Entries = append(result.Entries, o)
+	}
+	return result
+}
+
+// fieldResolvers settle fields that both sides changed to different values,
+// keyed by Go field name. They return false when they cannot decide.
+var fieldResolvers = map[string]func(base, ours, theirs reflect.Value) (reflect.Value, bool){
+	// Completed on both sides: the task was done when it was first done
+	"CompletedAt": func(base, ours, theirs reflect.Value) (reflect.Value, bool) {
+		if ours.IsNil() || theirs.IsNil() {
+			return reflect.Value{}, false
+		}
+		if theirs.Interface().(*time.Time).Before(*ours.Interface().(*time.Time)) {
+			return theirs, true
+		}
+		return ours, true
+	},
+	// Both sides recorded revisions: keep everyone's
+	"Revisions": func(base, ours, theirs reflect.Value) (reflect.Value, bool) {
+		merged := mergeRevisions(base.Interface().([]Revision), ours.Interface().([]Revision), theirs.Interface().([]Revision))
+		return reflect.ValueOf(merged), true
+	},
+}
+
+// mergeEntry starts from theirs and applies every field ours changed relative
+// to base. It returns the YAML names of fields left in conflict.
+func mergeEntry(base, ours, theirs Entry) (Entry, []string) {
+	merged := theirs
+	var conflicts []string
+
+	bv := reflect.ValueOf(base)
+	ov := reflect.ValueOf(ours)
+	tv := reflect.ValueOf(theirs)
+	mv := reflect.ValueOf(&merged).Elem()
+	for i := 0; i < mv.NumField(); i++ {
+		if valuesEqual(ov.Field(i), bv.Field(i)) {
+			continue // Only they changed it, or nobody did
+		}
+		if valuesEqual(tv.Field(i), bv.Field(i)) || valuesEqual(ov.Field(i), tv.Field(i)) {
+			mv.Field(i).Set(ov.Field(i))
+			continue
+		}
+
+		field := mv.Type().Field(i)
+		if resolve, ok := fieldResolvers[field.Name]; ok {
+			if v, ok := resolve(bv.Field(i), ov.Field(i), tv.Field(i)); ok {
+				mv.Field(i).Set(v)
+				continue
+			}
+		}
+		mv.Field(i).Set(ov.Field(i))
+		conflicts = append(conflicts, yamlFieldName(field))
+	}
+	return merged, conflicts
+}
+
+func yamlFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// EntryDiff summarizes how a list of entries changed, by entry ID
+type EntryDiff struct {
+	Added   []string
+	Changed []string
+	Removed []string
+}
+
+// Empty reports whether the two lists held the same entries
+func (d EntryDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Changed) == 0 && len(d.Removed) == 0
+}
+
+// DiffEntries compares two versions of a list by entry ID
+func DiffEntries(before, after []Entry) EntryDiff {
+	var diff EntryDiff
+	beforeByID := indexEntries(before)
+	afterByID := indexEntries(after)
+	for _, a := range after {
+		b, ok := beforeByID[a.ID]
+		if !ok {
+			diff.Added = append(diff.Added, a.ID)
+		} else if !entriesEqual(a, b) {
+			diff.Changed = append(diff.Changed, a.ID)
+		}
+	}
+	for _, b := range before {
+		if _, ok := afterByID[b.ID]; !ok {
+			diff.Removed = append(diff.Removed, b.ID)
+		}
+	}
+	return diff
+}
+
+func indexEntries(entries []Entry) map[string]Entry {
+	byID := make(map[string]Entry, len(entries))
+	for _, e := range entries {
+		byID[e.ID] = e
+	}
+	return byID
+}
+
+func sameEntries(a, b []Entry) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !entriesEqual(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func entriesEqual(a, b Entry) bool {
+	return valuesEqual(reflect.ValueOf(a), reflect.ValueOf(b))
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// valuesEqual is reflect.DeepEqual, except that times are compared with
+// time.Equal so a freshly parsed file matches the in-memory values
+func valuesEqual(a, b reflect.Value) bool {
+	if a.Type() == timeType {
+		return a.Interface().(time.Time).Equal(b.Interface().(time.Time))
+	}
+	switch a.Kind() {
+	case reflect.Pointer:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return valuesEqual(a.Elem(), b.Elem())
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !valuesEqual(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice:
+		// nil and empty are the same thing once written to YAML
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !valuesEqual(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+		iter := a.MapRange()
+		for iter.Next() {
+			bv := b.MapIndex(iter.Key())
+			if !bv.IsValid() || !valuesEqual(iter.Value(), bv) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
@@ -0,0 +1,159 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeEntriesConcurrentAdds(t *testing.T) {
+	base := AddEntry([]Entry{}, "Shared", "User", TypeNote)
+	ours := AddEntry(append([]Entry{}, base...), "Ours", "User", TypeNote)
+	theirs := AddEntry(append([]Entry{}, base...), "Theirs", "User", TypeNote)
+
+	merged := MergeEntries(base, ours, theirs)
+	if len(merged) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(merged))
+	}
+	if merged[0].Text != "Shared" || merged[1].Text != "Theirs" || merged[2].Text != "Ours" {
+		t.Errorf("Unexpected merge order: %s, %s, %s", merged[0].Text, merged[1].Text, merged[2].Text)
+	}
+}
+
+func TestMergeEntriesFieldLevel(t *testing.T) {
+	base := AddEntry([]Entry{}, "Task", "User", TypeTodo)
+	id := base[0].ID
+
+	ours := EditEntry(append([]Entry{}, base...), id, "Edited task", "User")
+	theirs := MarkDone(append([]Entry{}, base...), id, "User")
+
+	merged := MergeEntries(base, ours, theirs)
+	if len(merged) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(merged))
+	}
+	if merged[0].Text != "Edited task" {
+		t.Errorf("Expected our edit to survive, got '%s'", merged[0].Text)
+	}
+	if merged[0].CompletedAt == nil {
+		t.Error("Expected their completion to survive")
+	}
+}
+
+func TestMergeEntriesSameFieldOursWins(t *testing.T) {
+	base := AddEntry([]Entry{}, "Task", "User", TypeTodo)
+	id := base[0].ID
+
+	ours := EditEntry(append([]Entry{}, base...), id, "Ours", "User")
+	theirs := EditEntry(append([]Entry{}, base...), id, "Theirs", "User")
+
+	merged := MergeEntries(base, ours, theirs)
+	if merged[0].Text != "Ours" {
+		t.Errorf("Expected 'Ours', got '%s'", merged[0].Text)
+	}
+}
+
+func TestMergeEntriesDeletes(t *testing.T) {
+	base := []Entry{}
+	base = AddEntry(base, "Keep", "User", TypeTodo)
+	base = AddEntry(base, "Delete", "User", TypeTodo)
+
+	// We removed an entry they did not touch
+	ours := RemoveEntry(append([]Entry{}, base...), base[1].ID)
+	theirs := AddEntry(append([]Entry{}, base...), "New", "User", TypeTodo)
+	merged := MergeEntries(base, ours, theirs)
+	if len(merged) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(merged))
+	}
+	if _, ok := FindEntry(merged, base[1].ID); ok {
+		t.Error("Expected deleted entry to stay deleted")
+	}
+
+	// They completed the entry we removed, so their change is kept
+	theirs = MarkDone(append([]Entry{}, base...), base[1].ID, "User")
+	merged = MergeEntries(base, ours, theirs)
+	if _, ok := FindEntry(merged, base[1].ID); !ok {
+		t.Error("Expected entry changed by the other side to survive deletion")
+	}
+}
+
+func TestMergeEntriesUnchangedSide(t *testing.T) {
+	base := []Entry{}
+	base = AddEntry(base, "A", "User", TypeNote)
+	base = AddEntry(base, "B", "User", TypeNote)
+
+	// A reordering on our side is kept when nobody else wrote
+	ours := []Entry{base[1], base[0]}
+	merged := MergeEntries(base, ours, base)
+	if merged[0].Text != "B" {
+		t.Error("Expected our ordering to be kept")
+	}
+
+	theirs := AddEntry(append([]Entry{}, base...), "C", "User", TypeNote)
+	merged = MergeEntries(base, base, theirs)
+	if len(merged) != 3 {
+		t.Errorf("Expected their version, got %d entries", len(merged))
+	}
+}
+
+func TestDiffEntries(t *testing.T) {
+	before := []Entry{}
+	before = AddEntry(before, "Keep", "User", TypeTodo)
+	before = AddEntry(before, "Change", "User", TypeTodo)
+	before = AddEntry(before, "Remove", "User", TypeTodo)
+
+	after := append([]Entry{}, before...)
+	after = MarkDone(after, before[1].ID, "User")
+	after = RemoveEntry(after, before[2].ID)
+	after = AddEntry(after, "New", "User", TypeTodo)
+
+	diff := DiffEntries(before, after)
+	if len(diff.Added) != 1 || len(diff.Changed) != 1 || len(diff.Removed) != 1 {
+		t.Errorf("Unexpected diff: %+v", diff)
+	}
+	if diff.Changed[0] != before[1].ID {
+		t.Error("Wrong entry reported as changed")
+	}
+	if !DiffEntries(before, before).Empty() {
+		t.Error("Expected identical lists to produce an empty diff")
+	}
+}
+
+func TestMergeReportsConflicts(t *testing.T) {
+	base := AddEntry([]Entry{}, "Task", "User", TypeTodo)
+	id := base[0].ID
+
+	ours := EditEntry(append([]Entry{}, base...), id, "Ours", "User")
+	theirs := EditEntry(append([]Entry{}, base...), id, "Theirs", "User")
+
+	result := Merge(base, ours, theirs)
+	if len(result.Conflicts) != 1 {
+		t.Fatalf("Expected 1 conflict, got %d", len(result.Conflicts))
+	}
+	if result.Conflicts[0].ID != id || result.Conflicts[0].Field != "text" {
+		t.Errorf("Unexpected conflict: %+v", result.Conflicts[0])
+	}
+
+	// Identical changes on both sides are not a conflict
+	theirs = EditEntry(append([]Entry{}, base...), id, "Ours", "User")
+	if result := Merge(base, ours, theirs); len(result.Conflicts) != 0 {
+		t.Errorf("Expected no conflicts, got %+v", result.Conflicts)
+	}
+}
+
+func TestMergeCompletedOnBothSides(t *testing.T) {
+	base := AddEntry([]Entry{}, "Task", "User", TypeTodo)
+	early := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := early.Add(time.Hour)
+
+	ours := append([]Entry{}, base...)
+	ours[0].CompletedAt = &late
+	theirs := append([]Entry{}, base...)
+	theirs[0].CompletedAt = &early
+
+	result := Merge(base, ours, theirs)
+	if len(result.Conflicts) != 0 {
+		t.Errorf("Expected no conflicts, got %+v", result.Conflicts)
+	}
+	if !result.Entries[0].CompletedAt.Equal(early) {
+		t.Errorf("Expected the earliest completion, got %v", result.Entries[0].CompletedAt)
+	}
+}
@@ -0,0 +1,96 @@
+package core
+
+import "strings"
+
+// parseMentionToken recognizes @name tokens, ignoring trailing punctuation
+// so "thanks @bob," mentions bob
+func parseMentionToken(token string) (string, bool) {
+	if !strings.HasPrefix(token, "@") {
+		return "", false
+	}
+	name := strings.TrimRight(token[1:], ".,;:!?)")
+	if name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+func addAssignee(assignees []string, name string) []string {
+	for _, a := range assignees {
+		if strings.EqualFold(a, name) {
+			return assignees
+		}
+	}
+	return append(assignees, name)
+}
+
+// IsAssignedTo reports whether name is responsible for an entry. Entries
+// without assignees belong to whoever created them.
+func IsAssignedTo(e Entry, name string) bool {
+	if len(e.Assignees) == 0 {
+		return strings.EqualFold(e.CreatedBy, name)
+	}
+	for _, a := range e.Assignees {
+		if strings.EqualFold(a, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAssignedTodos returns the open todos name is responsible for
+func GetAssignedTodos(entries []Entry, name string) []Entry {
+	var assigned []Entry
+	for _, e := range GetActiveTodos(entries) {
+		if IsAssignedTo(e, name) {
+			assigned = append(assigned, e)
+		}
+	}
+	return assigned
+}
+
+// SetAssignees replaces the assignees of an entry. Names may include the leading @.
+func SetAssignees(entries []Entry, id string, names []string) []Entry {
+	var assignees []string
+	for _, n := range names {
+		if name, ok := parseMentionToken("@" + strings.TrimPrefix(n, "@")); ok {
+			assignees = addAssignee(assignees, name)
+		}
+	}
+	for i, e := range entries {
+		if e.ID == id {
+			entries[i].Assignees = assignees
+			return entries
+		}
+	}
+	return entries
+}
+
+// FormatPeople describes who created an entry and who it is assigned to,
+// e.g. "alice" or "alice -> bob, carol"
+func FormatPeople(e Entry) string {
+	if len(e.Assignees) == 0 || (len(e.Assignees) == 1 && e.Assignees[0] == e.CreatedBy) {
+		return e.CreatedBy
+	}
+	return e.CreatedBy + " -> " + strings.Join(e.Assignees, ", ")
+}
+
+// MigrateEntries upgrades entries written before CreatedBy and Assignees
+// existed. The legacy Author held the creator, or for todos the last
+// @mention, which replaced the creator; either way it is the best guess for
+// both who created the entry and, for todos, who it is assigned to.
+func MigrateEntries(entries []Entry) []Entry {
+	for i, e := range entries {
+		if e.Author == "" {
+			continue
+		}
+		if e.CreatedBy == "" {
+			entries[i].CreatedBy = e.Author
+		}
+		if e.Type == TypeTodo && len(e.Assignees) == 0 {
+			entries[i].Assignees = []string{e.Author}
+		}
+		entries[i].Author = ""
+	}
+	return entries
+}
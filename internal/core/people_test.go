@@ -0,0 +1,103 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAddEntryMentions(t *testing.T) {
+	entries := []Entry{}
+	entries = AddEntry(entries, "Review @bob @carol, @Bob", "alice", TypeTodo)
+
+	e := entries[0]
+	if e.CreatedBy != "alice" {
+		t.Errorf("Expected creator alice, got %s", e.CreatedBy)
+	}
+	if len(e.Assignees) != 2 || e.Assignees[0] != "bob" || e.Assignees[1] != "carol" {
+		t.Errorf("Unexpected assignees: %v", e.Assignees)
+	}
+	if e.Text != "Review" {
+		t.Errorf("Expected 'Review', got '%s'", e.Text)
+	}
+
+	// Notes record mentions but keep them in the text
+	entries = AddEntry(entries, "Paired with @dave today", "alice", TypeNote)
+	if len(entries[1].Assignees) != 1 || entries[1].Assignees[0] != "dave" {
+		t.Errorf("Unexpected note mentions: %v", entries[1].Assignees)
+	}
+	if entries[1].Text != "Paired with @dave today" {
+		t.Errorf("Expected note text to keep the mention, got '%s'", entries[1].Text)
+	}
+}
+
+func TestGetAssignedTodos(t *testing.T) {
+	entries := []Entry{}
+	entries = AddEntry(entries, "Mine, unassigned", "alice", TypeTodo)
+	entries = AddEntry(entries, "Mine, assigned @Alice", "bob", TypeTodo)
+	entries = AddEntry(entries, "Not mine @bob", "alice", TypeTodo)
+	entries = AddEntry(entries, "A note", "alice", TypeNote)
+
+	mine := GetAssignedTodos(entries, "alice")
+	if len(mine) != 2 {
+		t.Fatalf("Expected 2 todos, got %d", len(mine))
+	}
+	if mine[0].Text != "Mine, unassigned" || mine[1].Text != "Mine, assigned" {
+		t.Errorf("Unexpected todos: %s, %s", mine[0].Text, mine[1].Text)
+	}
+}
+
+func TestSetAssignees(t *testing.T) {
+	entries := AddEntry([]Entry{}, "Task", "alice", TypeTodo)
+	entries = SetAssignees(entries, entries[0].ID, []string{"@bob", "carol", "@"})
+	if len(entries[0].Assignees) != 2 {
+		t.Errorf("Unexpected assignees: %v", entries[0].Assignees)
+	}
+	entries = SetAssignees(entries, entries[0].ID, nil)
+	if len(entries[0].Assignees) != 0 {
+		t.Error("Expected assignees to be cleared")
+	}
+}
+
+func TestFormatPeople(t *testing.T) {
+	e := Entry{CreatedBy: "alice"}
+	if FormatPeople(e) != "alice" {
+		t.Errorf("Unexpected: %s", FormatPeople(e))
+	}
+	e.Assignees = []string{"bob", "carol"}
+	if FormatPeople(e) != "alice -> bob, carol" {
+		t.Errorf("Unexpected: %s", FormatPeople(e))
+	}
+}
+
+func TestMigrateLegacyAuthor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), dataFileName)
+	legacy := `- id: a
+  created_at: 2026-01-01T00:00:00Z
+  text: Old note
+  author: alice
+  type: note
+- id: b
+  created_at: 2026-01-01T00:00:00Z
+  text: Old task
+  author: bob
+  type: todo
+`
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := LoadEntries(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries[0].CreatedBy != "alice" || len(entries[0].Assignees) != 0 {
+		t.Errorf("Unexpected migrated note: %+v", entries[0])
+	}
+	if entries[1].CreatedBy != "bob" || len(entries[1].Assignees) != 1 || entries[1].Assignees[0] != "bob" {
+		t.Errorf("Unexpected migrated todo: %+v", entries[1])
+	}
+	if entries[0].Author != "" || entries[1].Author != "" {
+		t.Error("Expected the legacy author to be cleared")
+	}
+}
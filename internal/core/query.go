@@ -0,0 +1,430 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// QueryError reports a query that cannot be parsed, pointing at the offending token
+type QueryError struct {
+	Query  string
+	Column int // 1-based rune offset of the token
+	Token  string
+	Msg    string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("column %d: %s in %q", e.Column, e.Msg, e.Token)
+}
+
+// Caret renders the query with the offending token underlined
+func (e *QueryError) Caret() string {
+	width := max(1, utf8.RuneCountInString(e.Token))
+	return e.Query + "\n" + strings.Repeat(" ", e.Column-1) + strings.Repeat("^", width)
+}
+
+// QueryContext is what a query needs besides the entry it is matching
+type QueryContext struct {
+	Entries  []Entry // The whole list, to look up blockers
+	Workflow Workflow
+	Now      time.Time
+}
+
+// Query is a parsed filter expression; see ParseQuery
+type Query struct {
+	terms []queryTerm
+}
+
+type queryTerm struct {
+	negate bool
+	field  string // Canonical field name; "text" for bare words
+	op     string // Comparison for dates: =, <, <=, >, >=
+	value  string
+	date   time.Time
+}
+
+// queryFields maps field names and their aliases to canonical names
+var queryFields = map[string]string{
+	"type":      "type",
+	"author":    "author",
+	"by":        "author",
+	"assignee":  "assignee",
+	"assigned":  "assignee",
+	"tag":       "tag",
+	"status":    "status",
+	"priority":  "priority",
+	"prio":      "priority",
+	"created":   "created",
+	"completed": "completed",
+	"due":       "due",
+	"text":      "text",
+	"is":        "is",
+	"in":        "in",
+	"list":      "list",
+}
+
+// queryFlags are the values of is:, which also work as bare words
+var queryFlags = map[string]bool{
+	"done":    true,
+	"open":    true,
+	"blocked": true,
+	"overdue": true,
+	"todo":    true,
+	"note":    true,
+}
+
+// isQueryKey reports whether the part of a word before its colon looks like a
+// field name, so an unknown one is reported rather than searched for
+func isQueryKey(key string) bool {
+	return key != "" && strings.IndexFunc(key, func(r rune) bool { return !unicode.IsLetter(r) }) < 0
+}
+
+// ParseQuery parses a filter expression made of space-separated terms, all of
+// which must match:
+//
+//	word, "two words", text:word   text, people or tags contain it
+//	type:todo, type:note           entry type
+//	author:alice, assignee:bob     creator, or assigned person (also @bob)
+//	tag:api                        has the tag (also #api)
+//	status:in-review               workflow state
+//	priority:high                  priority (none for no priority)
+//	created:>2026-09-01            date comparisons with =, <, <=, > or >=
+//	completed:<=-7d, due:<+3d      on creation, completion and due dates
+//	done, open, blocked, overdue   state of todos (also is:done)
+//	list:ideas                     entries on a named list
+//	in:archive                     also search archived entries
+//
+// Dates are YYYY-MM-DD, today, yesterday, tomorrow, -7d (ago), +2w (ahead) or
+// a weekday. due:none matches todos without a due date. A leading - negates a
+// term, as in -done or -tag:wip. Words such as 10:30 or URLs, whose colon does
+// not follow a field name, are text.
+func ParseQuery(s string) (Query, error) {
+	return parseQuery(s, time.Now())
+}
+
+func parseQuery(s string, now time.Time) (Query, error) {
+	var q Query
+	tokens, err := tokenizeQuery(s)
+	if err != nil {
+		return q, err
+	}
+	for _, tok := range tokens {
+		term, msg := parseQueryTerm(tok.text, now)
+		if msg != "" {
+			return q, &QueryError{Query: s, Column: tok.column, Token: tok.text, Msg: msg}
+		}
+		q.terms = append(q.terms, term)
+	}
+	return q, nil
+}
+
+type queryToken struct {
+	text   string
+	column int
+}
+
+// tokenizeQuery splits on spaces outside double quotes
+func tokenizeQuery(s string) ([]queryToken, error) {
+	var tokens []queryToken
+	var cur strings.Builder
+	start, quoteAt := 0, 0
+	inQuote := false
+	column := 0
+	for _, r := range s {
+		column++
+		switch {
+		case r == '"':
+			if !inQuote {
+				quoteAt = column
+			}
+			if cur.Len() == 0 && !inQuote {
+				start = column
+			}
+			inQuote = !inQuote
+			cur.WriteRune(r)
+		case r == ' ' && !inQuote:
+			if cur.Len() > 0 {
+				tokens = append(tokens, queryToken{text: cur.String(), column: start})
+				cur.Reset()
+			}
+		default:
+			if cur.Len() == 0 {
+				start = column
+			}
+			cur.WriteRune(r)
+		}
+	}
+	if inQuote {
+		return nil, &QueryError{Query: s, Column: quoteAt, Token: `"`, Msg: "unclosed quote"}
+	}
+	if cur.Len() > 0 {
+		tokens = append(tokens, queryToken{text: cur.String(), column: start})
+	}
+	return tokens, nil
+}
+
+// parseQueryTerm parses one token, returning an error message on failure
+func parseQueryTerm(tok string, now time.Time) (queryTerm, string) {
+	var term queryTerm
+	if strings.HasPrefix(tok, "-") {
+		if len(tok) == 1 {
+			return term, "nothing to negate"
+		}
+		term.negate = true
+		tok = tok[1:]
+	}
+
+	switch {
+	case strings.HasPrefix(tok, "#"):
+		term.field, term.value = "tag", tok
+	case strings.HasPrefix(tok, "@"):
+		term.field, term.value = "assignee", tok[1:]
+	case strings.HasPrefix(tok, `"`):
+		term.field, term.value = "text", tok
+	default:
+		key, value, found := strings.Cut(tok, ":")
+		if !found {
+			if queryFlags[strings.ToLower(tok)] {
+				term.field, term.value = "is", strings.ToLower(tok)
+			} else {
+				term.field, term.value = "text", tok
+			}
+			break
+		}
+		if !isQueryKey(key) || strings.HasPrefix(value, "//") {
+			// Times like 10:30 and URLs are text, not a misspelled field
+			term.field, term.value = "text", tok
+			break
+		}
+		field, ok := queryFields[strings.ToLower(key)]
+		if !ok {
+			return term, fmt.Sprintf("unknown field %q", key)
+		}
+		term.field, term.value = field, value
+	}
+	term.value = strings.Trim(term.value, `"`)
+	if term.value == "" {
+		return term, "missing value"
+	}
+
+	switch term.field {
+	case "type":
+		term.value = strings.ToLower(term.value)
+		if term.value != string(TypeTodo) && term.value != string(TypeNote) {
+			return term, "type must be todo or note"
+		}
+	case "is":
+		term.value = strings.ToLower(term.value)
+		if !queryFlags[term.value] {
+			return term, fmt.Sprintf("unknown state %q (want done, open, blocked, overdue, todo or note)", term.value)
+		}
+	case "list":
+		name, ok := NormalizeList(term.value)
+		if !ok {
+			return term, "invalid list name"
+		}
+		term.value = name
+	case "in":
+		term.value = strings.ToLower(term.value)
+		if term.value != "archive" {
+			return term, "in: only accepts archive"
+		}
+		if term.negate {
+			return term, "in:archive cannot be negated"
+		}
+	case "tag":
+		tag, ok := NormalizeTag(term.value)
+		if !ok {
+			return term, "invalid tag"
+		}
+		term.value = tag
+	case "assignee":
+		term.value = strings.TrimPrefix(term.value, "@")
+	case "status":
+		term.value = NormalizeStatus(term.value)
+	case "priority":
+		p, ok := ParsePriority(term.value)
+		if !ok {
+			return term, "priority must be urgent, high, medium, low or none"
+		}
+		term.value = string(p)
+	case "created", "completed", "due":
+		term.op = "="
+		for _, op := range []string{"<=", ">=", "<", ">", "="} {
+			if strings.HasPrefix(term.value, op) {
+				term.op, term.value = op, term.value[len(op):]
+				break
+			}
+		}
+		if term.field == "due" && term.op == "=" && term.value == "none" {
+			break
+		}
+		date, err := parseQueryDate(term.value, now)
+		if err != nil {
+			return term, fmt.Sprintf("cannot parse date %q", term.value)
+		}
+		term.date = date
+	}
+	return term, ""
+}
+
+// parseQueryDate extends ParseDue with dates in the past
+func parseQueryDate(s string, now time.Time) (time.Time, error) {
+	expr := strings.ToLower(s)
+	today := startOfDay(now)
+	if expr == "yesterday" {
+		return today.AddDate(0, 0, -1), nil
+	}
+	if strings.HasPrefix(expr, "-") && len(expr) > 2 {
+		n, err := strconv.Atoi(expr[1 : len(expr)-1])
+		if err == nil && n >= 0 {
+			switch expr[len(expr)-1] {
+			case 'd':
+				return today.AddDate(0, 0, -n), nil
+			case 'w':
+				return today.AddDate(0, 0, -7*n), nil
+			case 'm':
+				return today.AddDate(0, -n, 0), nil
+			}
+		}
+	}
+	return ParseDue(expr, now)
+}
+
+// Empty reports whether the query has no terms and so matches everything
+func (q Query) Empty() bool {
+	return len(q.terms) == 0
+}
+
+// IncludesArchive reports whether the query asks for archived entries too.
+// Callers load the archive and add it to the entries they filter.
+func (q Query) IncludesArchive() bool {
+	for _, t := range q.terms {
+		if t.field == "in" {
+			return true
+		}
+	}
+	return false
+}
+
+// Text returns the free-text words of the query, for fuzzy matching or highlighting
+func (q Query) Text() string {
+	var words []string
+	for _, t := range q.terms {
+		if t.field == "text" && !t.negate {
+			words = append(words, t.value)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// WithoutText returns the query minus its positive free-text words, so a
+// caller can match those some other way (e.g. fuzzily) and keep the rest
+func (q Query) WithoutText() Query {
+	var rest Query
+	for _, t := range q.terms {
+		if t.field != "text" || t.negate {
+			rest.terms = append(rest.terms, t)
+		}
+	}
+	return rest
+}
+
+// Filter returns the entries matching every term of the query
+func (q Query) Filter(entries []Entry, ctx QueryContext) []Entry {
+	if q.Empty() {
+		return entries
+	}
+	var filtered []Entry
+	for _, e := range entries {
+		if q.Match(e, ctx) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// Match reports whether e satisfies every term of the query
+func (q Query) Match(e Entry, ctx QueryContext) bool {
+	for _, t := range q.terms {
+		if t.match(e, ctx) == t.negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (t queryTerm) match(e Entry, ctx QueryContext) bool {
+	switch t.field {
+	case "text":
+		return len(FilterEntries([]Entry{e}, t.value)) == 1
+	case "type":
+		return string(e.Type) == t.value
+	case "author":
+		return strings.EqualFold(e.CreatedBy, t.value)
+	case "assignee":
+		for _, a := range e.Assignees {
+			if strings.EqualFold(a, t.value) {
+				return true
+			}
+		}
+		return false
+	case "tag":
+		return HasTag(e, t.value)
+	case "status":
+		return e.Type == TypeTodo && StatusOf(e, ctx.Workflow) == t.value
+	case "priority":
+		return string(e.Priority) == t.value
+	case "created":
+		return compareDay(&e.CreatedAt, t.op, t.date)
+	case "completed":
+		return compareDay(e.CompletedAt, t.op, t.date)
+	case "due":
+		if t.value == "none" {
+			return e.Type == TypeTodo && e.DueAt == nil
+		}
+		return compareDay(e.DueAt, t.op, t.date)
+	case "list":
+		return ListOf(e) == t.value
+	case "in":
+		// A scope rather than a filter, see IncludesArchive
+		return true
+	case "is":
+		switch t.value {
+		case "done":
+			return e.Type == TypeTodo && e.CompletedAt != nil
+		case "open":
+			return e.Type == TypeTodo && e.CompletedAt == nil
+		case "blocked":
+			return IsBlocked(ctx.Entries, e)
+		case "overdue":
+			return GetDueState(e, ctx.Now) == DueOverdue
+		case "todo", "note":
+			return string(e.Type) == t.value
+		}
+	}
+	return false
+}
+
+// compareDay compares the calendar day of t with day
+func compareDay(t *time.Time, op string, day time.Time) bool {
+	if t == nil {
+		return false
+	}
+	c := startOfDay(t.In(day.Location())).Compare(day)
+	switch op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return c == 0
+}
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func queryFixture(now time.Time) []Entry {
+	day := func(s string) time.Time {
+		t, _ := time.ParseInLocation("2006-01-02", s, now.Location())
+		return t.Add(10 * time.Hour)
+	}
+	done := day("2026-09-20")
+	due := day("2026-09-01")
+	return []Entry{
+		{ID: "a", Type: TypeTodo, Text: "Fix api auth", CreatedBy: "alice", CreatedAt: day("2026-08-15"), Tags: []string{"api"}},
+		{ID: "b", Type: TypeTodo, Text: "Write docs", CreatedBy: "alice", CreatedAt: day("2026-09-05"), Tags: []string{"api", "docs"}, CompletedAt: &done},
+		{ID: "c", Type: TypeTodo, Text: "Deploy", CreatedBy: "bob", CreatedAt: day("2026-09-10"), Assignees: []string{"carol"}, Priority: PriorityHigh, DueAt: &due, BlockedBy: []string{"a"}},
+		{ID: "d", Type: TypeNote, Text: "Release went fine", CreatedBy: "alice", CreatedAt: day("2026-09-12"), Tags: []string{"api"}},
+	}
+}
+
+func runQuery(t *testing.T, entries []Entry, now time.Time, s string) string {
+	t.Helper()
+	q, err := parseQuery(s, now)
+	if err != nil {
+		t.Fatalf("ParseQuery(%q): %v", s, err)
+	}
+	ctx := QueryContext{Entries: entries, Workflow: DefaultWorkflow(), Now: now}
+	ids := ""
+	for _, e := range q.Filter(entries, ctx) {
+		ids += e.ID
+	}
+	return ids
+}
+
+func TestQuery(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.Local)
+	entries := queryFixture(now)
+
+	cases := map[string]string{
+		"": "abcd",
+		"type:todo author:alice created:>2026-09-01 -done tag:api": "",
+		"type:todo author:alice -done tag:api":                     "a",
+		"author:ALICE created:>=2026-09-05":                        "bd",
+		"created:2026-09-10":                                       "c",
+		"created:<-20d":                                            "abc",
+		"#docs":                                                    "b",
+		"-tag:api":                                                 "c",
+		"@carol":                                                   "c",
+		"assignee:carol priority:high":                             "c",
+		"blocked":                                                  "c",
+		"is:overdue":                                               "c",
+		"open type:todo":                                           "ac",
+		"due:none":                                                 "ab",
+		"completed:<=2026-09-20":                                   "b",
+		"status:done":                                              "b",
+		"api":                                                      "abd",
+		`"went fine"`:                                              "d",
+		"text:deploy":                                              "c",
+		"note":                                                     "d",
+		"10:30 http://x":                                           "",
+	}
+	for s, want := range cases {
+		if got := runQuery(t, entries, now, s); got != want {
+			t.Errorf("%q matched %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	cases := map[string]int{
+		"type:todo colour:red":  11,
+		"type:task":             1,
+		"tag:api created:>soon": 9,
+		`done "unclosed`:        6,
+		"open -":                6,
+		"priority:critical":     1,
+		"is:sleeping":           1,
+		"author:":               1,
+	}
+	for s, column := range cases {
+		_, err := ParseQuery(s)
+		var qe *QueryError
+		if !errors.As(err, &qe) {
+			t.Errorf("ParseQuery(%q): expected a QueryError, got %v", s, err)
+			continue
+		}
+		if qe.Column != column {
+			t.Errorf("ParseQuery(%q): error at column %d, want %d (%v)", s, qe.Column, column, err)
+		}
+	}
+
+	_, err := ParseQuery("type:todo colour:red")
+	if caret := err.(*QueryError).Caret(); caret != "type:todo colour:red\n          ^^^^^^^^^^" {
+		t.Errorf("Unexpected caret:\n%s", caret)
+	}
+}
+
+func TestQueryText(t *testing.T) {
+	q, err := ParseQuery("deploy tag:api -wip prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Text() != "deploy prod" {
+		t.Errorf("Unexpected text %q", q.Text())
+	}
+	if len(q.WithoutText().terms) != 2 {
+		t.Errorf("Expected 2 structured terms, got %d", len(q.WithoutText().terms))
+	}
+}
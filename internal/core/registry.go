@@ -0,0 +1,79 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+const projectsFileName = "projects"
+
+// ProjectsPath returns the location of the registry of projects tuido has run in
+func ProjectsPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, configDirName, projectsFileName), nil
+}
+
+// LoadProjects returns the data files of the registered projects, in the
+// order they were first seen
+func LoadProjects() ([]string, error) {
+	path, err := ProjectsPath()
+	if err != nil {
+		return nil, err
+	}
+	return readProjects(path)
+}
+
+func readProjects(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var projects []string
+	if err := yaml.Unmarshal(data, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// RegisterProject adds a data file to the registry. The registry is only
+// written when the project is new to it.
+func RegisterProject(dataFile string) error {
+	abs, err := filepath.Abs(dataFile)
+	if err != nil {
+		return err
+	}
+	path, err := ProjectsPath()
+	if err != nil {
+		return err
+	}
+	if projects, err := readProjects(path); err == nil && slices.Contains(projects, abs) {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return WithLock(path, func() error {
+		projects, err := readProjects(path)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(projects, abs) {
+			return nil
+		}
+		data, err := yaml.Marshal(append(projects, abs))
+		if err != nil {
+			return err
+		}
+		return writeFileAtomic(path, data, 0644)
+	})
+}
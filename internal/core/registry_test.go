@@ -0,0 +1,27 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRegisterProject(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	first := filepath.Join(dir, "one", dataFileName)
+	second := filepath.Join(dir, "two", dataFileName)
+
+	for _, path := range []string{first, second, first} {
+		if err := RegisterProject(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	projects, err := LoadProjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 2 || projects[0] != first || projects[1] != second {
+		t.Errorf("Expected each project once, in order, got %v", projects)
+	}
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Revision actions
+const (
+	RevisionCreate  = "create" // Only in timelines; creation is stored in CreatedAt and CreatedBy
+	RevisionEdit    = "edit"
+	RevisionDone    = "done"
+	RevisionUndone  = "undone"
+	RevisionStatus  = "status"
+	RevisionTrash   = "trash"
+	RevisionRestore = "restore"
+)
+
+// Revision records a change to an entry: who made it, when, and what it replaced
+type Revision struct {
+	At     time.Time `yaml:"at" json:"at"`
+	By     string    `yaml:"by" json:"by"`
+	Action string    `yaml:"action" json:"action"`                     // One of the Revision actions above
+	Text   string    `yaml:"text,omitempty" json:"text,omitempty"`     // The text before an edit
+	Status string    `yaml:"status,omitempty" json:"status,omitempty"` // The state a status change moved to
+}
+
+// addRevision appends a revision made now to an entry
+func addRevision(e *Entry, r Revision) {
+	r.At = time.Now()
+	e.Revisions = append(e.Revisions, r)
+}
+
+// Timeline returns the history of an entry, oldest first, starting with its creation
+func Timeline(e Entry) []Revision {
+	revisions := slices.Clone(e.Revisions)
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].At.Before(revisions[j].At)
+	})
+	return append([]Revision{{At: e.CreatedAt, By: e.CreatedBy, Action: RevisionCreate}}, revisions...)
+}
+
+// Describe renders what a revision did, e.g. `edited, was "Old text"`
+func (r Revision) Describe() string {
+	switch r.Action {
+	case RevisionCreate:
+		return "created"
+	case RevisionEdit:
+		return fmt.Sprintf("edited, was %q", r.Text)
+	case RevisionDone, RevisionUndone:
+		verb := "marked done"
+		if r.Action == RevisionUndone {
+			verb = "reopened"
+		}
+		if r.Status != "" {
+			verb += " (" + r.Status + ")"
+		}
+		return verb
+	case RevisionStatus:
+		return "moved to " + r.Status
+	case RevisionTrash:
+		return "moved to the trash"
+	case RevisionRestore:
+		return "restored from the trash"
+	}
+	return r.Action
+}
+
+// revisionKey identifies a revision when merging two histories
+func revisionKey(r Revision) string {
+	return strconv.FormatInt(r.At.UnixNano(), 10) + "\x00" + r.By + "\x00" + r.Action
+}
+
+// mergeRevisions applies the revisions ours added or dropped since base to
+// theirs. Histories only grow, except when an undo takes a revision back.
+func mergeRevisions(base, ours, theirs []Revision) []Revision {
+	inBase := map[string]bool{}
+	for _, r := range base {
+		inBase[revisionKey(r)] = true
+	}
+	inOurs := map[string]bool{}
+	for _, r := range ours {
+		inOurs[revisionKey(r)] = true
+	}
+
+	var merged []Revision
+	seen := map[string]bool{}
+	for _, r := range theirs {
+		key := revisionKey(r)
+		if inBase[key] && !inOurs[key] {
+			continue
+		}
+		seen[key] = true
+		merged = append(merged, r)
+	}
+	for _, r := range ours {
+		if key := revisionKey(r); !inBase[key] && !seen[key] {
+			merged = append(merged, r)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].At.Before(merged[j].At)
+	})
+	return merged
+}
@@ -0,0 +1,66 @@
+package core
+
+import "testing"
+
+func TestRevisionsRecordChanges(t *testing.T) {
+	entries := AddEntry([]Entry{}, "Draft", "alice", TypeTodo)
+	id := entries[0].ID
+
+	entries = EditEntry(entries, id, "Final", "bob")
+	entries = EditEntry(entries, id, "Final", "bob")
+	entries = MarkDone(entries, id, "carol")
+	entries = MarkDone(entries, id, "carol")
+	entries = MarkUndone(entries, id, "alice")
+	entries, err := SetStatus(entries, id, "in-progress", "bob", DefaultWorkflow())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timeline := Timeline(entries[0])
+	want := []string{
+		"alice created",
+		`bob edited, was "Draft"`,
+		"carol marked done",
+		"alice reopened",
+		"bob moved to in-progress",
+	}
+	if len(timeline) != len(want) {
+		t.Fatalf("Expected %d revisions, got %v", len(want), timeline)
+	}
+	for i, r := range timeline {
+		if got := r.By + " " + r.Describe(); got != want[i] {
+			t.Errorf("Revision %d: got %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestMergeKeepsBothSidesRevisions(t *testing.T) {
+	base := AddEntry([]Entry{}, "Task", "alice", TypeTodo)
+	id := base[0].ID
+
+	ours := EditEntry(append([]Entry{}, base...), id, "Task, reworded", "alice")
+	theirs := MarkDone(append([]Entry{}, base...), id, "bob")
+	result := Merge(base, ours, theirs)
+	if len(result.Conflicts) != 0 {
+		t.Errorf("Expected no conflicts, got %v", result.Conflicts)
+	}
+	revisions := result.Entries[0].Revisions
+	if len(revisions) != 2 || revisions[0].Action != RevisionEdit || revisions[1].Action != RevisionDone {
+		t.Errorf("Expected the edit and the completion, got %v", revisions)
+	}
+}
+
+func TestUndoDropsRevision(t *testing.T) {
+	before := AddEntry([]Entry{}, "Task", "alice", TypeTodo)
+	id := before[0].ID
+	after := MarkDone(append([]Entry(nil), before...), id, "alice")
+	op, _ := NewOperation(before, after)
+
+	// Someone edits the task before the completion is undone
+	current := EditEntry(append([]Entry(nil), after...), id, "Task, reworded", "bob")
+	undone := ApplyOperation(current, op.Invert())
+	revisions := undone[0].Revisions
+	if len(revisions) != 1 || revisions[0].Action != RevisionEdit {
+		t.Errorf("Expected only the edit to remain, got %v", revisions)
+	}
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MinShortIDLength is the shortest prefix handed out by ShortIDs
+const MinShortIDLength = 4
+
+var (
+	ErrRefNotFound  = errors.New("no entry matches")
+	ErrRefAmbiguous = errors.New("ambiguous entry reference")
+)
+
+// ShortIDs returns the shortest unique ID prefix for every entry, keyed by full ID.
+// Like git's abbreviated hashes, a short ID may grow as more entries are added.
+func ShortIDs(entries []Entry) map[string]string {
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, strings.ToLower(e.ID))
+	}
+	sort.Strings(ids)
+
+	// After sorting, the longest prefix an ID shares with any other ID
+	// is the one it shares with one of its neighbours
+	short := make(map[string]string, len(entries))
+	for i, id := range ids {
+		n := MinShortIDLength
+		if i > 0 {
+			n = max(n, commonPrefixLen(id, ids[i-1])+1)
+		}
+		if i < len(ids)-1 {
+			n = max(n, commonPrefixLen(id, ids[i+1])+1)
+		}
+		short[id] = id[:min(n, len(id))]
+	}
+
+	result := make(map[string]string, len(entries))
+	for _, e := range entries {
+		result[e.ID] = short[strings.ToLower(e.ID)]
+	}
+	return result
+}
+
+// ShortID returns the short ID of a single entry within entries
+func ShortID(entries []Entry, id string) string {
+	if s, ok := ShortIDs(entries)[id]; ok {
+		return s
+	}
+	return id
+}
+
+// ResolveRef maps a full ID or unique ID prefix to its entry
+func ResolveRef(entries []Entry, ref string) (Entry, error) {
+	ref = strings.ToLower(strings.TrimSpace(ref))
+	if ref == "" {
+		return Entry{}, fmt.Errorf("%w: empty reference", ErrRefNotFound)
+	}
+
+	var matches []Entry
+	for _, e := range entries {
+		id := strings.ToLower(e.ID)
+		if id == ref {
+			return e, nil
+		}
+		if strings.HasPrefix(id, ref) {
+			matches = append(matches, e)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return Entry{}, fmt.Errorf("%w %q", ErrRefNotFound, ref)
+	case 1:
+		return matches[0], nil
+	}
+
+	short := ShortIDs(entries)
+	candidates := make([]string, len(matches))
+	for i, e := range matches {
+		candidates[i] = short[e.ID]
+	}
+	return Entry{}, fmt.Errorf("%w %q matches %d entries: %s",
+		ErrRefAmbiguous, ref, len(matches), strings.Join(candidates, ", "))
+}
+
+func commonPrefixLen(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestShortIDs(t *testing.T) {
+	entries := []Entry{
+		{ID: "abcd1111-0000"},
+		{ID: "abcd2222-0000"},
+		{ID: "ffff0000-0000"},
+	}
+
+	short := ShortIDs(entries)
+	if short["abcd1111-0000"] != "abcd1" {
+		t.Errorf("Expected 'abcd1', got '%s'", short["abcd1111-0000"])
+	}
+	if short["abcd2222-0000"] != "abcd2" {
+		t.Errorf("Expected 'abcd2', got '%s'", short["abcd2222-0000"])
+	}
+	if short["ffff0000-0000"] != "ffff" {
+		t.Errorf("Expected 'ffff', got '%s'", short["ffff0000-0000"])
+	}
+}
+
+func TestShortIDsGenerated(t *testing.T) {
+	entries := []Entry{}
+	for i := 0; i < 50; i++ {
+		entries = AddEntry(entries, "Note", "User", TypeNote)
+	}
+
+	seen := make(map[string]bool)
+	for _, s := range ShortIDs(entries) {
+		if seen[s] {
+			t.Fatalf("Duplicate short ID %s", s)
+		}
+		seen[s] = true
+	}
+	for _, e := range entries {
+		got, err := ResolveRef(entries, ShortID(entries, e.ID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != e.ID {
+			t.Errorf("Short ID resolved to wrong entry")
+		}
+	}
+}
+
+func TestResolveRef(t *testing.T) {
+	entries := []Entry{
+		{ID: "abcd1111-0000", Text: "One"},
+		{ID: "abcd2222-0000", Text: "Two"},
+	}
+
+	e, err := ResolveRef(entries, "ABCD2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Text != "Two" {
+		t.Errorf("Expected 'Two', got '%s'", e.Text)
+	}
+
+	e, err = ResolveRef(entries, "abcd1111-0000")
+	if err != nil || e.Text != "One" {
+		t.Errorf("Expected full ID to resolve, got %v", err)
+	}
+
+	if _, err := ResolveRef(entries, "abcd"); !errors.Is(err, ErrRefAmbiguous) {
+		t.Errorf("Expected ambiguous error, got %v", err)
+	}
+	if _, err := ResolveRef(entries, "zzzz"); !errors.Is(err, ErrRefNotFound) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	if _, err := ResolveRef(entries, ""); !errors.Is(err, ErrRefNotFound) {
+		t.Errorf("Expected not found error for empty ref, got %v", err)
+	}
+}
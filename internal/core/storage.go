@@ -1,8 +1,12 @@
 package core
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -10,6 +14,12 @@ import (
 const configDirName = "tuido"
 const configFileName = "author"
 const dataFileName = ".tuido"
+const backupSuffix = ".bak"
+const corruptSuffix = ".corrupt"
+const projectConfigSuffix = ".config"
+
+// ErrCorrupt is returned by LoadEntries when the data file cannot be parsed
+var ErrCorrupt = errors.New("data file is corrupt")
 
 // LoadEntries reads the .tuido file from the current directory
 func LoadEntries(path string) ([]Entry, error) {
@@ -21,25 +31,156 @@ func LoadEntries(path string) ([]Entry, error) {
 		return nil, err
 	}
 
+	entries, err := decodeEntries(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorrupt, err)
+	}
+	return entries, nil
+}
+
+// SaveEntries writes the entries to the .tuido file.
+// The previous version is kept as a rolling backup, and the new content is
+// written to a temp file and renamed into place so a crash never truncates it.
+// Event logs are appended to instead, see saveEventLog.
+func SaveEntries(path string, entries []Entry) error {
+	if IsEventLogPath(path) {
+		return saveEventLog(path, entries)
+	}
+	if err := backupEntries(path); err != nil {
+		return fmt.Errorf("backup failed: %w", err)
+	}
+	return WriteEntries(path, entries)
+}
+
+// WriteEntries atomically writes entries to path without touching the backup.
+// It is meant for files that are not the project's data file, such as the
+// temporary files git hands to a merge driver.
+func WriteEntries(path string, entries []Entry) error {
+	if IsEventLogPath(path) {
+		return writeEventLog(path, []Event{newSnapshot(entries, time.Now())})
+	}
+	data, err := yaml.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// BackupPath returns the location of the rolling backup for a data file
+func BackupPath(path string) string {
+	return path + backupSuffix
+}
+
+// RestoreBackup replaces a data file with its backup and returns the restored entries.
+// The file being replaced is kept next to it with a .corrupt suffix for inspection.
+// The undo history is cleared, as its operations describe the replaced file.
+func RestoreBackup(path string) ([]Entry, error) {
 	var entries []Entry
+	err := WithLock(path, func() error {
+		data, err := os.ReadFile(BackupPath(path))
+		if err != nil {
+			return err
+		}
+		entries, err = decodeEntries(data)
+		if err != nil {
+			return fmt.Errorf("backup is unreadable too: %w", err)
+		}
+
+		if err := os.Rename(path, path+corruptSuffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := writeFileAtomic(path, data, 0644); err != nil {
+			return err
+		}
+		if err := os.Remove(HistoryPath(path)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// decodeEntries parses a YAML list or replays an event log
+func decodeEntries(data []byte) ([]Entry, error) {
+	if IsEventLog(data) {
+		entries, _, err := replayEvents(data)
+		return entries, err
+	}
+	return parseEntries(data)
+}
+
+func parseEntries(data []byte) ([]Entry, error) {
 	if len(data) == 0 {
 		return []Entry{}, nil
 	}
+	var entries []Entry
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []Entry{}
+	}
+	return MigrateEntries(entries), nil
+}
 
-	err = yaml.Unmarshal(data, &entries)
+// backupEntries copies the current data file to its backup. A file that no
+// longer parses is skipped so it can never overwrite the last good backup.
+func backupEntries(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return entries, nil
+	if _, err := decodeEntries(data); err != nil {
+		return nil
+	}
+	return writeFileAtomic(BackupPath(path), data, 0644)
 }
 
-// SaveEntries writes the entries to the .tuido file
-func SaveEntries(path string, entries []Entry) error {
-	data, err := yaml.Marshal(entries)
+// writeFileAtomic writes data to a temp file in the same directory, syncs it
+// and renames it over path. An existing file keeps its permissions.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) (err error) {
+	if info, statErr := os.Stat(path); statErr == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0644)
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// Persist the rename itself. Not every platform can sync a directory,
+	// so this is best effort.
+	if d, dirErr := os.Open(dir); dirErr == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
 }
 
 // LoadConfig reads the author name from the config file
@@ -89,5 +230,46 @@ func SaveConfig(cfg Config) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0644)
+	return writeFileAtomic(path, data, 0644)
+}
+
+// ProjectConfigPath returns the location of the project config for a data file.
+// An event log shares the config of the YAML file it replaces.
+func ProjectConfigPath(path string) string {
+	return strings.TrimSuffix(path, eventLogExt) + projectConfigSuffix
+}
+
+// LoadProjectConfig reads the project config next to a data file.
+// Settings that are not configured get their defaults.
+func LoadProjectConfig(path string) (ProjectConfig, error) {
+	cfg := ProjectConfig{Workflow: DefaultWorkflow(), ArchiveDays: DefaultArchiveDays}
+	data, err := os.ReadFile(ProjectConfigPath(path))
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	var loaded ProjectConfig
+	if err := yaml.Unmarshal(data, &loaded); err != nil {
+		return cfg, fmt.Errorf("%s: %w", filepath.Base(ProjectConfigPath(path)), err)
+	}
+	if len(loaded.Workflow.States) > 0 {
+		if err := loaded.Workflow.Validate(); err != nil {
+			return cfg, fmt.Errorf("%s: %w", filepath.Base(ProjectConfigPath(path)), err)
+		}
+		cfg.Workflow = loaded.Workflow
+	}
+	if loaded.TrashDays < 0 {
+		return cfg, fmt.Errorf("%s: trash_days must not be negative", filepath.Base(ProjectConfigPath(path)))
+	}
+	cfg.TrashDays = loaded.TrashDays
+	if loaded.ArchiveDays < 0 {
+		return cfg, fmt.Errorf("%s: archive_days must not be negative", filepath.Base(ProjectConfigPath(path)))
+	}
+	if loaded.ArchiveDays > 0 {
+		cfg.ArchiveDays = loaded.ArchiveDays
+	}
+	return cfg, nil
 }
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveAndLoadEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), dataFileName)
+
+	entries := AddEntry([]Entry{}, "Note 1", "User", TypeNote)
+	if err := SaveEntries(path, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadEntries(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 1 || loaded[0].Text != "Note 1" {
+		t.Errorf("Unexpected entries after reload: %+v", loaded)
+	}
+}
+
+func TestLoadEntriesMissing(t *testing.T) {
+	entries, err := LoadEntries(filepath.Join(t.TempDir(), dataFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected no entries, got %d", len(entries))
+	}
+}
+
+func TestSaveEntriesLeavesNoTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, dataFileName)
+
+	entries := AddEntry([]Entry{}, "Note 1", "User", TypeNote)
+	for i := 0; i < 3; i++ {
+		if err := SaveEntries(path, entries); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if f.Name() != dataFileName && f.Name() != dataFileName+backupSuffix {
+			t.Errorf("Unexpected file left behind: %s", f.Name())
+		}
+	}
+}
+
+func TestSaveEntriesKeepsBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), dataFileName)
+
+	entries := AddEntry([]Entry{}, "Note 1", "User", TypeNote)
+	if err := SaveEntries(path, entries); err != nil {
+		t.Fatal(err)
+	}
+	entries = AddEntry(entries, "Note 2", "User", TypeNote)
+	if err := SaveEntries(path, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	backup, err := LoadEntries(BackupPath(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backup) != 1 {
+		t.Errorf("Expected backup to hold the previous version, got %d entries", len(backup))
+	}
+}
+
+func TestLoadEntriesCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), dataFileName)
+	if err := os.WriteFile(path, []byte("- id: [unterminated"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := LoadEntries(path)
+	if !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Expected ErrCorrupt, got %v", err)
+	}
+}
+
+func TestCorruptFileDoesNotReplaceBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), dataFileName)
+
+	entries := AddEntry([]Entry{}, "Note 1", "User", TypeNote)
+	if err := SaveEntries(path, entries); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveEntries(path, entries); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("garbage: ["), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveEntries(path, []Entry{}); err != nil {
+		t.Fatal(err)
+	}
+
+	backup, err := LoadEntries(BackupPath(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backup) != 1 {
+		t.Errorf("Expected the last good backup to survive, got %d entries", len(backup))
+	}
+}
+
+func TestRestoreBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), dataFileName)
+
+	for range 2 {
+		err := UpdateEntries(path, func(entries []Entry) ([]Entry, error) {
+			return AddEntry(entries[:0], "Note 1", "User", TypeNote), nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(path, []byte("garbage: ["), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := RestoreBackup(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored) != 1 {
+		t.Errorf("Expected 1 restored entry, got %d", len(restored))
+	}
+	if _, err := LoadEntries(path); err != nil {
+		t.Errorf("Expected restored file to load, got %v", err)
+	}
+	if _, err := os.Stat(path + corruptSuffix); err != nil {
+		t.Errorf("Expected corrupt file to be kept: %v", err)
+	}
+	if _, err := UndoEntries(path, false); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Expected the history of the replaced file to be cleared, got %v", err)
+	}
+}
+
+func TestWriteEntriesSkipsBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "merge_file")
+
+	entries := AddEntry([]Entry{}, "Note 1", "User", TypeNote)
+	if err := WriteEntries(path, entries); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteEntries(path, entries); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(BackupPath(path)); !os.IsNotExist(err) {
+		t.Errorf("Expected no backup file, got %v", err)
+	}
+}
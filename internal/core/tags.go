@@ -0,0 +1,109 @@
+package core
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// NormalizeTag validates a tag name, with or without its leading #, and
+// returns it lowercased. Tags start with a letter so "#1" stays an issue number.
+func NormalizeTag(s string) (string, bool) {
+	tag := strings.ToLower(strings.TrimPrefix(s, "#"))
+	if tag == "" {
+		return "", false
+	}
+	for i, r := range tag {
+		if i == 0 && !unicode.IsLetter(r) {
+			return "", false
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' && r != '/' {
+			return "", false
+		}
+	}
+	return tag, true
+}
+
+// parseTagToken recognizes inline tag tokens like #backend
+func parseTagToken(token string) (string, bool) {
+	if !strings.HasPrefix(token, "#") {
+		return "", false
+	}
+	return NormalizeTag(token)
+}
+
+// splitTags removes the #tag tokens from text and returns them separately
+func splitTags(text string) (string, []string) {
+	if !strings.Contains(text, "#") {
+		return text, nil
+	}
+	var words, tags []string
+	for _, w := range strings.Fields(text) {
+		if tag, ok := parseTagToken(w); ok {
+			tags = addTag(tags, tag)
+			continue
+		}
+		words = append(words, w)
+	}
+	return strings.Join(words, " "), tags
+}
+
+// TextWithTags returns an entry's text with its tags appended as #tag tokens,
+// the form in which it is edited
+func TextWithTags(e Entry) string {
+	text := e.Text
+	for _, t := range e.Tags {
+		text += " #" + t
+	}
+	return text
+}
+
+func addTag(tags []string, tag string) []string {
+	for _, t := range tags {
+		if t == tag {
+			return tags
+		}
+	}
+	return append(tags, tag)
+}
+
+// HasTag reports whether an entry carries the given (normalized) tag
+func HasTag(e Entry, tag string) bool {
+	for _, t := range e.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterByTag returns the entries carrying tag, which may include the leading #
+func FilterByTag(entries []Entry, tag string) []Entry {
+	tag, ok := NormalizeTag(tag)
+	if !ok {
+		return nil
+	}
+	var filtered []Entry
+	for _, e := range entries {
+		if HasTag(e, tag) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// AllTags returns every tag in use, sorted
+func AllTags(entries []Entry) []string {
+	seen := make(map[string]struct{})
+	for _, e := range LiveEntries(entries) {
+		for _, t := range e.Tags {
+			seen[t] = struct{}{}
+		}
+	}
+	tags := make([]string, 0, len(seen))
+	for t := range seen {
+		tags = append(tags, t)
+	}
+	sort.Strings(tags)
+	return tags
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	valid := map[string]string{
+		"#Backend":    "backend",
+		"docs":        "docs",
+		"#api/v2":     "api/v2",
+		"#good_first": "good_first",
+	}
+	for in, want := range valid {
+		got, ok := NormalizeTag(in)
+		if !ok || got != want {
+			t.Errorf("NormalizeTag(%q) = %q, %v; want %q", in, got, ok, want)
+		}
+	}
+	for _, in := range []string{"#", "#1", "#bug!", ""} {
+		if _, ok := NormalizeTag(in); ok {
+			t.Errorf("Expected NormalizeTag(%q) to fail", in)
+		}
+	}
+}
+
+func TestAddEntryTags(t *testing.T) {
+	entries := []Entry{}
+	entries = AddEntry(entries, "Fix crash #backend #Bug #bug in #1", "User", TypeTodo)
+
+	e := entries[0]
+	if len(e.Tags) != 2 || e.Tags[0] != "backend" || e.Tags[1] != "bug" {
+		t.Errorf("Unexpected tags: %v", e.Tags)
+	}
+	if e.Text != "Fix crash in #1" {
+		t.Errorf("Expected 'Fix crash in #1', got '%s'", e.Text)
+	}
+
+	entries = AddEntry(entries, "Wrote the guide #docs", "User", TypeNote)
+	if !HasTag(entries[1], "docs") {
+		t.Error("Expected notes to be tagged too")
+	}
+}
+
+func TestFilterByTag(t *testing.T) {
+	entries := []Entry{}
+	entries = AddEntry(entries, "One #api", "User", TypeTodo)
+	entries = AddEntry(entries, "Two #docs", "User", TypeTodo)
+	entries = AddEntry(entries, "Three #api #docs", "User", TypeNote)
+
+	filtered := FilterByTag(entries, "#API")
+	if len(filtered) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(filtered))
+	}
+	if filtered[0].Text != "One" || filtered[1].Text != "Three" {
+		t.Error("Wrong entries filtered")
+	}
+
+	if len(FilterEntries(entries, "docs")) != 2 {
+		t.Error("Expected FilterEntries to match tags")
+	}
+}
+
+func TestAllTags(t *testing.T) {
+	entries := []Entry{}
+	entries = AddEntry(entries, "One #zeta #api", "User", TypeTodo)
+	entries = AddEntry(entries, "Two #api", "User", TypeTodo)
+
+	if got := strings.Join(AllTags(entries), ","); got != "api,zeta" {
+		t.Errorf("Expected 'api,zeta', got '%s'", got)
+	}
+}
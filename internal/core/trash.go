@@ -0,0 +1,97 @@
+package core
+
+import (
+	"sort"
+	"time"
+)
+
+// Trashed reports whether an entry was removed and sits in the trash
+func (e Entry) Trashed() bool {
+	return e.DeletedAt != nil
+}
+
+// LiveEntries returns the entries that are not in the trash
+func LiveEntries(entries []Entry) []Entry {
+	var live []Entry
+	for _, e := range entries {
+		if !e.Trashed() {
+			live = append(live, e)
+		}
+	}
+	return live
+}
+
+// TrashEntries moves entries to the trash. They stay in the file, hidden from
+// every view, until RestoreEntries brings them back or they are purged.
+func TrashEntries(entries []Entry, ids map[string]struct{}, author string) []Entry {
+	now := time.Now()
+	for i, e := range entries {
+		if _, found := ids[e.ID]; found && !e.Trashed() {
+			addRevision(&entries[i], Revision{By: author, Action: RevisionTrash})
+			entries[i].DeletedAt = &now
+		}
+	}
+	return entries
+}
+
+// RestoreEntries takes entries out of the trash
+func RestoreEntries(entries []Entry, ids map[string]struct{}, author string) []Entry {
+	for i, e := range entries {
+		if _, found := ids[e.ID]; found && e.Trashed() {
+			addRevision(&entries[i], Revision{By: author, Action: RevisionRestore})
+			entries[i].DeletedAt = nil
+		}
+	}
+	return entries
+}
+
+// GetTrashedEntries returns the entries in the trash, most recently removed first
+func GetTrashedEntries(entries []Entry) []Entry {
+	var trashed []Entry
+	for _, e := range entries {
+		if e.Trashed() {
+			trashed = append(trashed, e)
+		}
+	}
+	sort.SliceStable(trashed, func(i, j int) bool {
+		return trashed[i].DeletedAt.After(*trashed[j].DeletedAt)
+	})
+	return trashed
+}
+
+// PurgeTrash permanently deletes the entries that went to the trash before cutoff.
+// It returns the remaining entries and the number purged.
+func PurgeTrash(entries []Entry, cutoff time.Time) ([]Entry, int) {
+	ids := make(map[string]struct{})
+	for _, e := range entries {
+		if e.Trashed() && e.DeletedAt.Before(cutoff) {
+			ids[e.ID] = struct{}{}
+		}
+	}
+	if len(ids) == 0 {
+		return entries, 0
+	}
+	return RemoveEntries(entries, ids), len(ids)
+}
+
+// PurgeExpiredTrash permanently deletes the entries that have been in the
+// trash of a data file for more than days. The file is only written if
+// something expired.
+func PurgeExpiredTrash(path string, days int) (int, error) {
+	if days <= 0 {
+		return 0, nil
+	}
+	purged := 0
+	err := WithLock(path, func() error {
+		entries, err := LoadEntries(path)
+		if err != nil {
+			return err
+		}
+		entries, purged = PurgeTrash(entries, time.Now().AddDate(0, 0, -days))
+		if purged == 0 {
+			return nil
+		}
+		return SaveEntries(path, entries)
+	})
+	return purged, err
+}
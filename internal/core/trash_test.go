@@ -0,0 +1,60 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrashAndRestore(t *testing.T) {
+	entries := AddEntry([]Entry{}, "Keep", "alice", TypeTodo)
+	entries = AddEntry(entries, "Drop", "alice", TypeTodo)
+	id := entries[1].ID
+
+	entries = TrashEntries(entries, map[string]struct{}{id: {}}, "bob")
+	if len(entries) != 2 || !entries[1].Trashed() {
+		t.Fatalf("Expected the entry to stay in the list as trashed, got %v", entries)
+	}
+	if active := GetActiveTodos(entries); len(active) != 1 || active[0].Text != "Keep" {
+		t.Errorf("Expected trashed entries to be hidden, got %v", active)
+	}
+	if trashed := GetTrashedEntries(entries); len(trashed) != 1 || trashed[0].ID != id {
+		t.Errorf("Expected the trashed entry, got %v", trashed)
+	}
+
+	entries = RestoreEntries(entries, map[string]struct{}{id: {}}, "bob")
+	if entries[1].Trashed() || len(GetActiveTodos(entries)) != 2 {
+		t.Errorf("Expected the entry to be restored, got %v", entries)
+	}
+	timeline := Timeline(entries[1])
+	if len(timeline) != 3 || timeline[1].Action != RevisionTrash || timeline[2].Action != RevisionRestore {
+		t.Errorf("Expected trash and restore revisions, got %v", timeline)
+	}
+}
+
+func TestTrashedBlockerDoesNotBlock(t *testing.T) {
+	entries := AddEntry([]Entry{}, "Blocker", "User", TypeTodo)
+	entries = AddEntry(entries, "Blocked", "User", TypeTodo)
+	entries, err := AddBlocker(entries, entries[1].ID, entries[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries = TrashEntries(entries, map[string]struct{}{entries[0].ID: {}}, "User")
+	if open := OpenBlockers(entries, entries[1]); len(open) != 0 {
+		t.Errorf("Expected no open blockers, got %v", open)
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	entries := AddEntry([]Entry{}, "Old", "User", TypeNote)
+	entries = AddEntry(entries, "Recent", "User", TypeNote)
+	entries = AddEntry(entries, "Live", "User", TypeNote)
+	old := time.Now().AddDate(0, 0, -40)
+	recent := time.Now().AddDate(0, 0, -2)
+	entries[0].DeletedAt = &old
+	entries[1].DeletedAt = &recent
+
+	entries, purged := PurgeTrash(entries, time.Now().AddDate(0, 0, -30))
+	if purged != 1 || len(entries) != 2 || entries[0].Text != "Recent" {
+		t.Errorf("Expected only the old entry to be purged, got %d, %v", purged, entries)
+	}
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrParentCycle = errors.New("an entry cannot be its own ancestor")
+
+// TreeItem is an entry positioned in the subtask hierarchy
+type TreeItem struct {
+	Entry       Entry
+	Depth       int
+	HasChildren bool
+}
+
+// FlattenTree orders entries depth first, each parent followed by its
+// subtasks. Siblings keep their relative order from entries, and entries
+// whose parent is not in the list are treated as roots. The children of IDs
+// in collapsed are left out.
+func FlattenTree(entries []Entry, collapsed map[string]bool) []TreeItem {
+	present := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		present[e.ID] = true
+	}
+	children := make(map[string][]Entry)
+	var roots []Entry
+	for _, e := range entries {
+		if e.ParentID != "" && e.ParentID != e.ID && present[e.ParentID] {
+			children[e.ParentID] = append(children[e.ParentID], e)
+		} else {
+			roots = append(roots, e)
+		}
+	}
+
+	items := make([]TreeItem, 0, len(entries))
+	visited := make(map[string]bool, len(entries))
+	var walk func(e Entry, depth int, hidden bool)
+	walk = func(e Entry, depth int, hidden bool) {
+		if visited[e.ID] {
+			return
+		}
+		visited[e.ID] = true
+		kids := children[e.ID]
+		if !hidden {
+			items = append(items, TreeItem{Entry: e, Depth: depth, HasChildren: len(kids) > 0})
+		}
+		for _, c := range kids {
+			walk(c, depth+1, hidden || collapsed[e.ID])
+		}
+	}
+	for _, r := range roots {
+		walk(r, 0, false)
+	}
+
+	// Entries caught in a parent cycle (e.g. after a bad merge) have no root;
+	// show them at the top level rather than dropping them
+	for _, e := range entries {
+		if !visited[e.ID] {
+			walk(e, 0, false)
+		}
+	}
+	return items
+}
+
+// SetParent makes id a subtask of parentID; an empty parentID makes it a top-level entry
+func SetParent(entries []Entry, id string, parentID string) ([]Entry, error) {
+	if parentID != "" {
+		if _, ok := FindEntry(entries, parentID); !ok {
+			return entries, fmt.Errorf("no entry with id %q", parentID)
+		}
+		// Walk up from the new parent; reaching id would close a loop
+		for cur := parentID; cur != ""; {
+			if cur == id {
+				return entries, ErrParentCycle
+			}
+			p, ok := FindEntry(entries, cur)
+			if !ok {
+				break
+			}
+			cur = p.ParentID
+		}
+	}
+
+	for i, e := range entries {
+		if e.ID == id {
+			entries[i].ParentID = parentID
+			// Subtasks live on their parent's list
+			if parent, ok := FindEntry(entries, parentID); ok {
+				entries = MoveToList(entries, map[string]struct{}{id: {}}, ListOf(parent))
+			}
+			return entries, nil
+		}
+	}
+	return entries, fmt.Errorf("no entry with id %q", id)
+}
+
+// GetOpenSubtasks returns the open todos below id, at any depth
+func GetOpenSubtasks(entries []Entry, id string) []Entry {
+	var open []Entry
+	seen := map[string]bool{id: true}
+	queue := []string{id}
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		for _, e := range entries {
+			if e.ParentID != parent || seen[e.ID] {
+				continue
+			}
+			seen[e.ID] = true
+			queue = append(queue, e.ID)
+			if e.Type == TypeTodo && e.CompletedAt == nil && !e.Trashed() {
+				open = append(open, e)
+			}
+		}
+	}
+	return open
+}
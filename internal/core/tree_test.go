@@ -0,0 +1,105 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFlattenTree(t *testing.T) {
+	entries := []Entry{
+		{ID: "a", Text: "Ship release", Type: TypeTodo},
+		{ID: "b", Text: "Write notes", Type: TypeTodo, ParentID: "a"},
+		{ID: "c", Text: "Other", Type: TypeTodo},
+		{ID: "d", Text: "Proofread", Type: TypeTodo, ParentID: "b"},
+		{ID: "e", Text: "Orphan", Type: TypeTodo, ParentID: "gone"},
+	}
+
+	items := FlattenTree(entries, nil)
+	var got []string
+	for _, item := range items {
+		got = append(got, strings.Repeat(".", item.Depth)+item.Entry.ID)
+	}
+	if strings.Join(got, " ") != "a .b ..d c e" {
+		t.Errorf("Unexpected tree order: %v", got)
+	}
+	if !items[0].HasChildren || items[3].HasChildren {
+		t.Error("HasChildren not set correctly")
+	}
+
+	items = FlattenTree(entries, map[string]bool{"a": true})
+	if len(items) != 3 || items[0].Entry.ID != "a" || !items[0].HasChildren {
+		t.Errorf("Expected collapsed parent to hide its subtree, got %d items", len(items))
+	}
+}
+
+func TestFlattenTreeCycle(t *testing.T) {
+	entries := []Entry{
+		{ID: "a", ParentID: "b"},
+		{ID: "b", ParentID: "a"},
+	}
+	if items := FlattenTree(entries, nil); len(items) != 2 {
+		t.Errorf("Expected both entries in a cycle to be shown, got %d", len(items))
+	}
+}
+
+func TestSetParent(t *testing.T) {
+	entries := []Entry{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+
+	entries, err := SetParent(entries, "b", "a")
+	if err != nil || entries[1].ParentID != "a" {
+		t.Fatalf("SetParent failed: %v", err)
+	}
+	entries, _ = SetParent(entries, "c", "b")
+
+	if _, err := SetParent(entries, "a", "c"); !errors.Is(err, ErrParentCycle) {
+		t.Errorf("Expected ErrParentCycle, got %v", err)
+	}
+	if _, err := SetParent(entries, "a", "a"); !errors.Is(err, ErrParentCycle) {
+		t.Errorf("Expected ErrParentCycle for self, got %v", err)
+	}
+	if _, err := SetParent(entries, "a", "missing"); err == nil {
+		t.Error("Expected error for a missing parent")
+	}
+
+	entries, err = SetParent(entries, "b", "")
+	if err != nil || entries[1].ParentID != "" {
+		t.Errorf("Expected b to become top-level, got %q, %v", entries[1].ParentID, err)
+	}
+}
+
+func TestGetOpenSubtasks(t *testing.T) {
+	entries := []Entry{}
+	entries = AddEntry(entries, "Parent", "User", TypeTodo)
+	entries = AddEntry(entries, "Child", "User", TypeTodo)
+	entries = AddEntry(entries, "Grandchild", "User", TypeTodo)
+	entries = AddEntry(entries, "Done child", "User", TypeTodo)
+	parent := entries[0].ID
+	entries, _ = SetParent(entries, entries[1].ID, parent)
+	entries, _ = SetParent(entries, entries[2].ID, entries[1].ID)
+	entries, _ = SetParent(entries, entries[3].ID, parent)
+	entries = MarkDone(entries, entries[3].ID, "User")
+
+	open := GetOpenSubtasks(entries, parent)
+	if len(open) != 2 || open[0].Text != "Child" || open[1].Text != "Grandchild" {
+		t.Errorf("Unexpected open subtasks: %v", open)
+	}
+	if len(GetOpenSubtasks(entries, entries[2].ID)) != 0 {
+		t.Error("Expected a leaf to have no open subtasks")
+	}
+}
+
+func TestExportNestedChecklist(t *testing.T) {
+	entries := []Entry{}
+	entries = AddEntry(entries, "Parent", "User", TypeTodo)
+	entries = AddEntry(entries, "Child", "User", TypeTodo)
+	entries, _ = SetParent(entries, entries[1].ID, entries[0].ID)
+
+	md := GenerateExportMarkdown(entries)
+	if !strings.Contains(md, "\n- [ ] **User**") || !strings.Contains(md, "\n  - [ ] **User**") {
+		t.Errorf("Expected a nested checklist, got:\n%s", md)
+	}
+	if strings.Index(md, "Parent") > strings.Index(md, "Child") {
+		t.Error("Expected the parent before its subtask")
+	}
+}
@@ -9,15 +9,49 @@ const (
 	TypeTodo EntryType = "todo"
 )
 
+type Priority string
+
+const (
+	PriorityNone   Priority = ""
+	PriorityUrgent Priority = "urgent"
+	PriorityHigh   Priority = "high"
+	PriorityMedium Priority = "medium"
+	PriorityLow    Priority = "low"
+)
+
+// Entry is a single note or todo. The json tags are part of the versioned
+// machine-readable schema (see SchemaVersion), so treat renames as breaking.
 type Entry struct {
-	ID          string     `yaml:"id"`
-	CreatedAt   time.Time  `yaml:"created_at"`
-	CompletedAt *time.Time `yaml:"completed_at,omitempty"` // Pointer to allow null
-	Text        string     `yaml:"text"`
-	Author      string     `yaml:"author"`
-	Type        EntryType  `yaml:"type"`
+	ID          string     `yaml:"id" json:"id"`
+	CreatedAt   time.Time  `yaml:"created_at" json:"created_at"`
+	CompletedAt *time.Time `yaml:"completed_at,omitempty" json:"completed_at,omitempty"` // Pointer to allow null
+	Text        string     `yaml:"text" json:"text"`
+	CreatedBy   string     `yaml:"created_by" json:"created_by"`
+	Assignees   []string   `yaml:"assignees,omitempty" json:"assignees,omitempty"` // Every @mention, without the @
+	Type        EntryType  `yaml:"type" json:"type"`
+	List        string     `yaml:"list,omitempty" json:"list,omitempty"` // Named list; empty for DefaultList
+	Priority    Priority   `yaml:"priority,omitempty" json:"priority,omitempty"`
+	DueAt       *time.Time `yaml:"due_at,omitempty" json:"due_at,omitempty"`           // Start of the day the todo is due
+	Tags        []string   `yaml:"tags,omitempty" json:"tags,omitempty"`               // Lowercase, without the leading #
+	ParentID    string     `yaml:"parent_id,omitempty" json:"parent_id,omitempty"`     // Set on subtasks
+	BlockedBy   []string   `yaml:"blocked_by,omitempty" json:"blocked_by,omitempty"`   // IDs of todos that must be done first
+	Status      string     `yaml:"status,omitempty" json:"status,omitempty"`           // Workflow state; empty means derived from CompletedAt
+	Revisions   []Revision `yaml:"revisions,omitempty" json:"revisions,omitempty"`     // Edits and completions, oldest first
+	DeletedAt   *time.Time `yaml:"deleted_at,omitempty" json:"deleted_at,omitempty"`   // Set while the entry is in the trash
+	ArchivedAt  *time.Time `yaml:"archived_at,omitempty" json:"archived_at,omitempty"` // Set on entries moved to an archive file
+
+	// Deprecated: files written before CreatedBy and Assignees existed stored
+	// either the creator or the last @mention here. LoadEntries migrates it.
+	Author string `yaml:"author,omitempty" json:"-"`
 }
 
 type Config struct {
 	Author string `yaml:"author"`
 }
+
+// ProjectConfig holds the settings stored next to a project's data file
+type ProjectConfig struct {
+	Workflow    Workflow `yaml:"workflow,omitempty"`
+	TrashDays   int      `yaml:"trash_days,omitempty"`   // Purge entries from the trash after this many days; 0 keeps them
+	ArchiveDays int      `yaml:"archive_days,omitempty"` // Archive completed todos older than this many days
+}
@@ -0,0 +1,263 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const historySuffix = ".history"
+
+// MaxHistory is the number of operations kept for undo
+const MaxHistory = 100
+
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+	// ErrHistory is returned, wrapped, when a change was saved but could not
+	// be recorded for undo
+	ErrHistory = errors.New("could not record undo history")
+)
+
+// Change is the before and after state of one entry. Before is nil for an
+// added entry and After is nil for a removed one.
+type Change struct {
+	ID     string `yaml:"id"`
+	Index  int    `yaml:"index"` // Position in the list, to put removed entries back in place
+	Before *Entry `yaml:"before,omitempty"`
+	After  *Entry `yaml:"after,omitempty"`
+}
+
+// Operation is one undoable change to the list
+type Operation struct {
+	Label   string    `yaml:"label"` // add, edit, done, undone, trash, restore, remove or update
+	At      time.Time `yaml:"at"`
+	Changes []Change  `yaml:"changes"`
+}
+
+// History is the persisted undo and redo stacks, most recent last
+type History struct {
+	Undo []Operation `yaml:"undo,omitempty"`
+	Redo []Operation `yaml:"redo,omitempty"`
+}
+
+// NewOperation records the difference between two versions of the list.
+// It returns false if nothing changed.
+func NewOperation(before, after []Entry) (Operation, bool) {
+	diff := DiffEntries(before, after)
+	if diff.Empty() {
+		return Operation{}, false
+	}
+
+	op := Operation{At: time.Now()}
+	beforeByID := indexEntries(before)
+	afterByID := indexEntries(after)
+	for i, e := range after {
+		b, existed := beforeByID[e.ID]
+		if existed && entriesEqual(b, e) {
+			continue
+		}
+		c := Change{ID: e.ID, Index: i, After: &e}
+		if existed {
+			c.Before = &b
+		}
+		op.Changes = append(op.Changes, c)
+	}
+	for i, b := range before {
+		if _, ok := afterByID[b.ID]; !ok {
+			op.Changes = append(op.Changes, Change{ID: b.ID, Index: i, Before: &b})
+		}
+	}
+	op.Label = operationLabel(op.Changes)
+	return op, true
+}
+
+// operationLabel names what the changes did, for messages like "Undid edit"
+func operationLabel(changes []Change) string {
+	label := ""
+	for _, c := range changes {
+		l := changeLabel(c.Before, c.After)
+		if label != "" && label != l {
+			return "update"
+		}
+		label = l
+	}
+	return label
+}
+
+// changeLabel names the change of a single entry; before or after is nil for
+// an added or removed entry
+func changeLabel(before, after *Entry) string {
+	switch {
+	case before == nil:
+		return "add"
+	case after == nil:
+		return "remove"
+	case before.DeletedAt == nil && after.DeletedAt != nil:
+		return RevisionTrash
+	case before.DeletedAt != nil && after.DeletedAt == nil:
+		return RevisionRestore
+	case before.CompletedAt == nil && after.CompletedAt != nil:
+		return "done"
+	case before.CompletedAt != nil && after.CompletedAt == nil:
+		return "undone"
+	case before.Text != after.Text:
+		return "edit"
+	}
+	return "update"
+}
+
+// Describe summarizes the operation, e.g. `edit "Fix login"` or `remove 3 entries`
+func (op Operation) Describe() string {
+	if len(op.Changes) != 1 {
+		return fmt.Sprintf("%s %d entries", op.Label, len(op.Changes))
+	}
+	e := op.Changes[0].After
+	if e == nil {
+		e = op.Changes[0].Before
+	}
+	text := []rune(e.Text)
+	if len(text) > 40 {
+		text = append(text[:39], '…')
+	}
+	return fmt.Sprintf("%s %q", op.Label, string(text))
+}
+
+// Invert returns the operation that reverses op
+func (op Operation) Invert() Operation {
+	inv := Operation{Label: op.Label, At: op.At, Changes: make([]Change, len(op.Changes))}
+	for i, c := range op.Changes {
+		inv.Changes[i] = Change{ID: c.ID, Index: c.Index, Before: c.After, After: c.Before}
+	}
+	return inv
+}
+
+// ApplyOperation brings the entries an operation touched to its After state.
+// Entries changed since by someone else are merged field by field, like Merge
+// does, so undoing a completion does not revert a later edit of the text.
+func ApplyOperation(entries []Entry, op Operation) []Entry {
+	for _, c := range op.Changes {
+		i := -1
+		for k, e := range entries {
+			if e.ID == c.ID {
+				i = k
+				break
+			}
+		}
+
+		switch {
+		case c.After == nil:
+			if i >= 0 {
+				entries = append(entries[:i:i], entries[i+1:]...)
+			}
+		case i < 0:
+			at := min(max(c.Index, 0), len(entries))
+			entries = append(entries[:at:at], append([]Entry{*c.After}, entries[at:]...)...)
+		case c.Before == nil:
+			entries[i] = *c.After
+		default:
+			entries[i], _ = mergeEntry(*c.Before, *c.After, entries[i])
+		}
+	}
+	return entries
+}
+
+// Record pushes an operation onto the undo stack and clears the redo stack
+func (h *History) Record(op Operation) {
+	h.Undo = append(h.Undo, op)
+	if len(h.Undo) > MaxHistory {
+		h.Undo = h.Undo[len(h.Undo)-MaxHistory:]
+	}
+	h.Redo = nil
+}
+
+// HistoryPath returns the location of the undo history for a data file
+func HistoryPath(path string) string {
+	return path + historySuffix
+}
+
+// LoadHistory reads the undo history of a data file; a missing file is an empty history
+func LoadHistory(path string) (History, error) {
+	var h History
+	data, err := os.ReadFile(HistoryPath(path))
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return h, err
+	}
+	err = yaml.Unmarshal(data, &h)
+	return h, err
+}
+
+// SaveHistory atomically writes the undo history of a data file
+func SaveHistory(path string, h History) error {
+	data, err := yaml.Marshal(h)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(HistoryPath(path), data, 0644)
+}
+
+// recordHistory adds the change from before to after to the undo history.
+// The caller holds the data file's lock, so the history stays in step with
+// the write that made the change.
+func recordHistory(path string, before, after []Entry) error {
+	op, changed := NewOperation(before, after)
+	if !changed {
+		return nil
+	}
+	h, err := LoadHistory(path)
+	if err == nil {
+		h.Record(op)
+		err = SaveHistory(path, h)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrHistory, err)
+	}
+	return nil
+}
+
+// UndoEntries reverts the most recent operation in the data file and moves it
+// to the redo stack. With redo set, it re-applies the most recently undone
+// operation instead. It returns the operation that was undone or redone.
+func UndoEntries(path string, redo bool) (Operation, error) {
+	var op Operation
+	err := WithLock(path, func() error {
+		h, err := LoadHistory(path)
+		if err != nil {
+			return err
+		}
+		from, to := &h.Undo, &h.Redo
+		if redo {
+			from, to = &h.Redo, &h.Undo
+		}
+		if len(*from) == 0 {
+			if redo {
+				return ErrNothingToRedo
+			}
+			return ErrNothingToUndo
+		}
+		op = (*from)[len(*from)-1]
+		*from = (*from)[:len(*from)-1]
+
+		entries, err := LoadEntries(path)
+		if err != nil {
+			return err
+		}
+		if redo {
+			entries = ApplyOperation(entries, op)
+		} else {
+			entries = ApplyOperation(entries, op.Invert())
+		}
+		if err := SaveEntries(path, entries); err != nil {
+			return err
+		}
+		*to = append(*to, op)
+		return SaveHistory(path, h)
+	})
+	return op, err
+}
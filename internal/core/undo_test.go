@@ -0,0 +1,96 @@
+package core
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestOperationUndoRedo(t *testing.T) {
+	before := []Entry{}
+	before = AddEntry(before, "One", "User", TypeTodo)
+	before = AddEntry(before, "Two", "User", TypeTodo)
+	before = AddEntry(before, "Three", "User", TypeNote)
+
+	after := append([]Entry(nil), before...)
+	after = RemoveEntry(after, before[1].ID)
+	op, ok := NewOperation(before, after)
+	if !ok || op.Label != "remove" || op.Describe() != `remove "Two"` {
+		t.Fatalf("Unexpected operation %q", op.Describe())
+	}
+
+	restored := ApplyOperation(append([]Entry(nil), after...), op.Invert())
+	if len(restored) != 3 || restored[1].Text != "Two" {
+		t.Fatalf("Expected Two back in its place, got %v", restored)
+	}
+	redone := ApplyOperation(restored, op)
+	if len(redone) != 2 || redone[1].Text != "Three" {
+		t.Errorf("Expected Two removed again, got %v", redone)
+	}
+
+	if _, ok := NewOperation(before, before); ok {
+		t.Error("Expected no operation without changes")
+	}
+}
+
+func TestUndoKeepsLaterChanges(t *testing.T) {
+	before := AddEntry([]Entry{}, "Task", "User", TypeTodo)
+	id := before[0].ID
+
+	after := append([]Entry(nil), before...)
+	after = MarkDone(after, id, "User")
+	op, _ := NewOperation(before, after)
+	if op.Label != "done" {
+		t.Errorf("Expected a done operation, got %q", op.Label)
+	}
+
+	// Someone edits the text before we undo the completion
+	current := EditEntry(append([]Entry(nil), after...), id, "Task, reworded", "User")
+	current = ApplyOperation(current, op.Invert())
+	if current[0].CompletedAt != nil || current[0].Text != "Task, reworded" {
+		t.Errorf("Expected the completion undone and the edit kept, got %+v", current[0])
+	}
+}
+
+func TestUndoEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), dataFileName)
+	if _, err := UndoEntries(path, false); !errors.Is(err, ErrNothingToUndo) {
+		t.Fatalf("Expected ErrNothingToUndo, got %v", err)
+	}
+
+	err := UpdateEntries(path, func(entries []Entry) ([]Entry, error) {
+		return AddEntry(entries, "Persisted", "User", TypeNote), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	op, err := UndoEntries(path, false)
+	if err != nil || op.Label != "add" {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if entries, _ := LoadEntries(path); len(entries) != 0 {
+		t.Errorf("Expected the add undone, got %d entries", len(entries))
+	}
+
+	if _, err := UndoEntries(path, true); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := LoadEntries(path); len(entries) != 1 {
+		t.Errorf("Expected the add redone, got %d entries", len(entries))
+	}
+	if _, err := UndoEntries(path, true); !errors.Is(err, ErrNothingToRedo) {
+		t.Errorf("Expected ErrNothingToRedo, got %v", err)
+	}
+}
+
+func TestHistoryRecord(t *testing.T) {
+	var h History
+	h.Redo = []Operation{{Label: "edit"}}
+	for i := 0; i < MaxHistory+5; i++ {
+		h.Record(Operation{Label: "add"})
+	}
+	if len(h.Undo) != MaxHistory || len(h.Redo) != 0 {
+		t.Errorf("Expected %d undo and no redo operations, got %d and %d", MaxHistory, len(h.Undo), len(h.Redo))
+	}
+}
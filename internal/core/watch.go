@@ -0,0 +1,19 @@
+package core
+
+// WatchFile reports changes to a data file on the returned channel until stop
+// is called. Notifications are coalesced: while one is pending, further
+// changes do not queue up, so receivers should reload the whole file.
+func WatchFile(path string) (changes <-chan struct{}, stop func(), err error) {
+	ch := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+	stop, err = watchFile(path, notify)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ch, stop, nil
+}
@@ -0,0 +1,55 @@
+//go:build linux
+
+package core
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// watchFile uses inotify on the parent directory. The file itself cannot be
+// watched because SaveEntries replaces it with a rename on every write.
+func watchFile(path string, notify func()) (func(), error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	const mask = syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO | syscall.IN_MOVED_FROM |
+		syscall.IN_CREATE | syscall.IN_DELETE
+	if _, err := syscall.InotifyAddWatch(fd, filepath.Dir(path), mask); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+
+	// A non-blocking descriptor is handled by the runtime poller, so
+	// closing the file unblocks the pending Read below
+	f := os.NewFile(uintptr(fd), "inotify")
+	name := []byte(filepath.Base(path))
+
+	go func() {
+		buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+		for {
+			n, err := f.Read(buf)
+			if err != nil {
+				return
+			}
+			for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+				event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+				start := offset + syscall.SizeofInotifyEvent
+				end := start + int(event.Len)
+				eventName := bytes.TrimRight(buf[start:end], "\x00")
+				if bytes.Equal(eventName, name) {
+					notify()
+				}
+				offset = end
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { f.Close() }) }, nil
+}
@@ -0,0 +1,49 @@
+//go:build !linux
+
+package core
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// pollInterval is how often the data file is checked on platforms without inotify
+const pollInterval = time.Second
+
+// watchFile polls the file's modification time and size
+func watchFile(path string, notify func()) (func(), error) {
+	type fileState struct {
+		modTime time.Time
+		size    int64
+		exists  bool
+	}
+	stat := func() fileState {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fileState{}
+		}
+		return fileState{modTime: info.ModTime(), size: info.Size(), exists: true}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		last := stat()
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if cur := stat(); cur != last {
+					last = cur
+					notify()
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }, nil
+}
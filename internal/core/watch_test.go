@@ -0,0 +1,29 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), dataFileName)
+
+	changes, stop, err := WatchFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	// Give the polling fallback a different modification time to notice
+	time.Sleep(10 * time.Millisecond)
+	if err := SaveEntries(path, AddEntry([]Entry{}, "Note", "User", TypeNote)); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a change notification")
+	}
+}
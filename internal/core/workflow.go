@@ -0,0 +1,208 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnknownStatus     = errors.New("unknown status")
+	ErrInvalidTransition = errors.New("transition not allowed")
+)
+
+// State is one step of a workflow. Reaching a terminal state completes the todo.
+type State struct {
+	Name     string `yaml:"name"`
+	Terminal bool   `yaml:"terminal,omitempty"`
+}
+
+// Workflow is the per-project state machine for todos. Transitions maps a
+// state to the states it may move to; without any, every move is allowed.
+type Workflow struct {
+	States      []State             `yaml:"states"`
+	Transitions map[string][]string `yaml:"transitions,omitempty"`
+}
+
+// DefaultWorkflow is used by projects that do not configure their own
+func DefaultWorkflow() Workflow {
+	return Workflow{
+		States: []State{
+			{Name: "todo"},
+			{Name: "in-progress"},
+			{Name: "in-review"},
+			{Name: "done", Terminal: true},
+			{Name: "wont-do", Terminal: true},
+		},
+		Transitions: map[string][]string{
+			"todo":        {"in-progress", "done", "wont-do"},
+			"in-progress": {"todo", "in-review", "done", "wont-do"},
+			"in-review":   {"in-progress", "done", "wont-do"},
+			"done":        {"todo", "in-progress"},
+			"wont-do":     {"todo"},
+		},
+	}
+}
+
+// NormalizeStatus lowercases a state name and joins words with dashes
+func NormalizeStatus(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), "-")
+}
+
+// Validate checks that the workflow has an open and a terminal state and
+// that transitions only name known states
+func (w Workflow) Validate() error {
+	seen := make(map[string]bool)
+	var open, terminal bool
+	for _, s := range w.States {
+		if s.Name == "" || s.Name != NormalizeStatus(s.Name) {
+			return fmt.Errorf("invalid state name %q (use lowercase words joined by dashes)", s.Name)
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("state %q is defined twice", s.Name)
+		}
+		seen[s.Name] = true
+		open = open || !s.Terminal
+		terminal = terminal || s.Terminal
+	}
+	if !open || !terminal {
+		return errors.New("workflow needs at least one open and one terminal state")
+	}
+	for from, targets := range w.Transitions {
+		if !seen[from] {
+			return fmt.Errorf("transition from %w %q", ErrUnknownStatus, from)
+		}
+		for _, to := range targets {
+			if !seen[to] {
+				return fmt.Errorf("transition to %w %q", ErrUnknownStatus, to)
+			}
+		}
+	}
+	return nil
+}
+
+// State looks up a state by name
+func (w Workflow) State(name string) (State, bool) {
+	for _, s := range w.States {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return State{}, false
+}
+
+// Initial is the state new todos start in: the first open state
+func (w Workflow) Initial() string {
+	for _, s := range w.States {
+		if !s.Terminal {
+			return s.Name
+		}
+	}
+	return ""
+}
+
+// DoneState is the state completed todos without an explicit status are in: the first terminal state
+func (w Workflow) DoneState() string {
+	for _, s := range w.States {
+		if s.Terminal {
+			return s.Name
+		}
+	}
+	return ""
+}
+
+// CanTransition reports whether a todo may move from one state to another
+func (w Workflow) CanTransition(from, to string) bool {
+	if from == to {
+		return false
+	}
+	if len(w.Transitions) == 0 {
+		return true
+	}
+	return slices.Contains(w.Transitions[from], to)
+}
+
+// StatusOf returns the workflow state of a todo. Entries without a status,
+// or whose status disagrees with CompletedAt (e.g. after MarkDone or a merge),
+// fall back to the initial or done state.
+func StatusOf(e Entry, w Workflow) string {
+	if s, ok := w.State(e.Status); ok && s.Terminal == (e.CompletedAt != nil) {
+		return s.Name
+	}
+	if e.CompletedAt != nil {
+		return w.DoneState()
+	}
+	return w.Initial()
+}
+
+// SetStatus moves a todo to another state. Entering a terminal state sets
+// CompletedAt and leaving one clears it, so done/undone keep working.
+func SetStatus(entries []Entry, id string, status string, author string, w Workflow) ([]Entry, error) {
+	target, ok := w.State(NormalizeStatus(status))
+	if !ok {
+		return entries, fmt.Errorf("%w %q", ErrUnknownStatus, status)
+	}
+	for i, e := range entries {
+		if e.ID != id {
+			continue
+		}
+		if e.Type != TypeTodo {
+			return entries, fmt.Errorf("only todos have a status")
+		}
+		from := StatusOf(e, w)
+		if !w.CanTransition(from, target.Name) {
+			return entries, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, target.Name)
+		}
+
+		action := RevisionStatus
+		if target.Terminal && e.CompletedAt == nil {
+			action = RevisionDone
+		} else if !target.Terminal && e.CompletedAt != nil {
+			action = RevisionUndone
+		}
+		addRevision(&entries[i], Revision{By: author, Action: action, Status: target.Name})
+
+		entries[i].Status = target.Name
+		if target.Terminal && e.CompletedAt == nil {
+			now := time.Now()
+			entries[i].CompletedAt = &now
+		} else if !target.Terminal {
+			entries[i].CompletedAt = nil
+		}
+		return entries, nil
+	}
+	return entries, fmt.Errorf("no entry with id %q", id)
+}
+
+// CompleteTodo moves a todo to the workflow's done state, unless it is
+// already completed. It fails like SetStatus if the workflow does not allow
+// the move from the todo's current state.
+func CompleteTodo(entries []Entry, id string, author string, w Workflow) ([]Entry, error) {
+	e, ok := FindEntry(entries, id)
+	if ok && e.CompletedAt != nil {
+		return entries, nil
+	}
+	return SetStatus(entries, id, w.DoneState(), author, w)
+}
+
+// ReopenTodo moves a completed todo back to the workflow's initial state
+func ReopenTodo(entries []Entry, id string, author string, w Workflow) ([]Entry, error) {
+	e, ok := FindEntry(entries, id)
+	if ok && e.CompletedAt == nil {
+		return entries, nil
+	}
+	return SetStatus(entries, id, w.Initial(), author, w)
+}
+
+// FilterByStatus returns the todos currently in the given state
+func FilterByStatus(entries []Entry, status string, w Workflow) []Entry {
+	var filtered []Entry
+	for _, e := range LiveEntries(entries) {
+		if e.Type == TypeTodo && StatusOf(e, w) == status {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetStatus(t *testing.T) {
+	w := DefaultWorkflow()
+	entries := AddEntry([]Entry{}, "Ship it", "User", TypeTodo)
+	id := entries[0].ID
+
+	if got := StatusOf(entries[0], w); got != "todo" {
+		t.Errorf("Expected new todos to start in todo, got %q", got)
+	}
+
+	entries, err := SetStatus(entries, id, "In Progress", "User", w)
+	if err != nil || entries[0].Status != "in-progress" || entries[0].CompletedAt != nil {
+		t.Fatalf("Expected in-progress, got %q, %v", entries[0].Status, err)
+	}
+
+	entries, err = SetStatus(entries, id, "wont-do", "User", w)
+	if err != nil || entries[0].CompletedAt == nil {
+		t.Fatalf("Expected a terminal state to complete the todo: %v", err)
+	}
+	if len(GetActiveTodos(entries)) != 0 {
+		t.Error("Expected wont-do todos to be inactive")
+	}
+
+	if _, err := SetStatus(entries, id, "in-review", "User", w); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Expected ErrInvalidTransition, got %v", err)
+	}
+	if _, err := SetStatus(entries, id, "blocked", "User", w); !errors.Is(err, ErrUnknownStatus) {
+		t.Errorf("Expected ErrUnknownStatus, got %v", err)
+	}
+
+	entries = MarkUndone(entries, id, "User")
+	if got := StatusOf(entries[0], w); got != "todo" {
+		t.Errorf("Expected MarkUndone to reset the status, got %q", got)
+	}
+}
+
+func TestCompleteTodoFollowsWorkflow(t *testing.T) {
+	w := Workflow{
+		States:      []State{{Name: "backlog"}, {Name: "doing"}, {Name: "shipped", Terminal: true}},
+		Transitions: map[string][]string{"backlog": {"doing"}, "doing": {"shipped"}, "shipped": {"doing"}},
+	}
+	entries := AddEntry([]Entry{}, "Ship it", "User", TypeTodo)
+	id := entries[0].ID
+
+	if _, err := CompleteTodo(entries, id, "User", w); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("Expected backlog -> shipped to be refused, got %v", err)
+	}
+	if entries[0].CompletedAt != nil {
+		t.Error("Expected a refused completion to leave the todo open")
+	}
+
+	entries, _ = SetStatus(entries, id, "doing", "User", w)
+	entries, err := CompleteTodo(entries, id, "User", w)
+	if err != nil || entries[0].CompletedAt == nil || StatusOf(entries[0], w) != "shipped" {
+		t.Fatalf("Expected the todo to be shipped, got %q, %v", entries[0].Status, err)
+	}
+	if entries, err = CompleteTodo(entries, id, "User", w); err != nil {
+		t.Errorf("Expected completing a completed todo to do nothing, got %v", err)
+	}
+
+	// Reopening goes to the initial state, which shipped may not move to
+	if _, err := ReopenTodo(entries, id, "User", w); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Expected shipped -> backlog to be refused, got %v", err)
+	}
+}
+
+func TestStatusOfFallsBack(t *testing.T) {
+	w := DefaultWorkflow()
+	entries := AddEntry([]Entry{}, "Task", "User", TypeTodo)
+	entries, _ = SetStatus(entries, entries[0].ID, "in-review", "User", w)
+
+	// A merge can combine one side's status with the other's completion
+	entries = MarkDone(entries, entries[0].ID, "User")
+	entries[0].Status = "in-review"
+	if got := StatusOf(entries[0], w); got != "done" {
+		t.Errorf("Expected completed todos to be done, got %q", got)
+	}
+
+	entries[0].Status = "removed-from-config"
+	entries[0].CompletedAt = nil
+	if got := StatusOf(entries[0], w); got != "todo" {
+		t.Errorf("Expected unknown states to fall back to todo, got %q", got)
+	}
+}
+
+func TestWorkflowValidate(t *testing.T) {
+	if err := DefaultWorkflow().Validate(); err != nil {
+		t.Errorf("Default workflow is invalid: %v", err)
+	}
+
+	invalid := []Workflow{
+		{States: []State{{Name: "open"}}},
+		{States: []State{{Name: "open"}, {Name: "open", Terminal: true}}},
+		{States: []State{{Name: "In Review"}, {Name: "done", Terminal: true}}},
+		{States: []State{{Name: "open"}, {Name: "done", Terminal: true}}, Transitions: map[string][]string{"open": {"closed"}}},
+	}
+	for i, w := range invalid {
+		if err := w.Validate(); err == nil {
+			t.Errorf("Expected workflow %d to be invalid", i)
+		}
+	}
+
+	free := Workflow{States: []State{{Name: "open"}, {Name: "done", Terminal: true}}}
+	if !free.CanTransition("done", "open") {
+		t.Error("Expected workflows without transitions to allow any move")
+	}
+}
+
+func TestLoadProjectConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), dataFileName)
+
+	cfg, err := LoadProjectConfig(path)
+	if err != nil || cfg.Workflow.Initial() != "todo" {
+		t.Fatalf("Expected the default workflow without a config file: %v", err)
+	}
+
+	config := `workflow:
+  states:
+    - name: backlog
+    - name: doing
+    - name: shipped
+      terminal: true
+`
+	if err := os.WriteFile(ProjectConfigPath(path), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = LoadProjectConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Workflow.Initial() != "backlog" || cfg.Workflow.DoneState() != "shipped" {
+		t.Errorf("Unexpected workflow: %+v", cfg.Workflow)
+	}
+
+	os.WriteFile(ProjectConfigPath(path), []byte("workflow:\n  states:\n    - name: only\n"), 0644)
+	if _, err := LoadProjectConfig(path); err == nil {
+		t.Error("Expected an invalid workflow to be rejected")
+	}
+}
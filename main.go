@@ -1,9 +1,12 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -23,7 +26,9 @@ var (
 	cGreen   = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
 	cYellow  = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
 	cRed     = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	cUrgent  = lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true)
 	cGray    = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	cMatch   = lipgloss.NewStyle().Foreground(lipgloss.Color("3")).Bold(true).Underline(true)
 )
 
 // --- Application States ---
@@ -34,6 +39,12 @@ const (
 	stateSelectTask
 	stateEditTaskInput
 	stateHistoryView
+	stateUpcomingView
+	stateMineView
+	stateConfirmDone
+	stateBoardView
+	stateFind
+	stateLogView
 )
 
 type selectMode int
@@ -43,33 +54,75 @@ const (
 	modeUndone
 	modeEdit
 	modeRemove
+	modePriority
+	modeDue
+	modeSubtask
+	modeFold
+	modeBlock
+	modeBlocker
+	modeUnblock
+	modeStatus
+	modeLog
+	modeTrash
+	modeMove
 )
 
 // --- Model ---
 type model struct {
-	state      appState
-	filePath   string
-	configPath string
-	author     string
+	state       appState
+	filePath    string
+	configPath  string
+	author      string
+	workflow    core.Workflow // States todos move through, from the project config
+	list        string        // Named list the views show and new entries go to
+	archiveDays int           // Age in days of the completed todos /archive moves, from the project config
 
 	// Data
-	entries []core.Entry
+	entries  []core.Entry
+	base     []core.Entry      // Entries as last loaded from disk, for merging on save
+	shortIDs map[string]string // Full ID -> shortest unique prefix
+	archive  []core.Entry      // Archived entries, loaded once a view asks for them
 
 	// Input & Viewport
 	textInput textinput.Model
 	viewport  viewport.Model
+	width     int // Terminal size, for layouts that do not scroll
+	height    int
 
 	// Selection Logic
 	cursor        int
 	selectList    []core.Entry        // Items being selected
 	selectedIDs   map[string]struct{} // IDs of selected items (for multiselect)
 	selectionMode selectMode          // Are we marking done, editing, or removing?
+	pendingPrio   core.Priority       // Priority applied in modePriority
+	pendingDue    *time.Time          // Due date applied in modeDue (nil clears it)
+	pendingText   string              // Subtask text added in modeSubtask
+	pendingBlock  string              // Task picked in modeBlock; modeBlocker adds its blocker
+	pendingStatus string              // State applied in modeStatus
+	pendingList   string              // List modeMove moves entries to
+	logID         string              // Entry whose timeline stateLogView shows
+	histArchive   bool                // stateHistoryView includes archived todos
+
+	// Board cursor: column (workflow state) and card within it
+	boardCol int
+	boardRow int
+
+	// Main view filter
+	tagFilter string          // Only entries with this tag are shown when set
+	query     string          // Fuzzy search; the main view and selection lists show matches only
+	collapsed map[string]bool // Parents whose subtasks are hidden
+
+	// Live reload
+	changes <-chan struct{} // Notifications from the file watcher
 
 	// Messages
-	msg string
+	msg     string
+	corrupt bool // Data file failed to parse; saving is blocked until /recover
 }
 
-func initialModel() model {
+// initialModel sets up the TUI for the data file given by --file, or the
+// one resolveDataFile finds if that is empty
+func initialModel(fileFlag string) model {
 	// Setup text input
 	ti := textinput.New()
 	ti.Placeholder = "Type a note, /todo, or command..."
@@ -80,100 +133,706 @@ func initialModel() model {
 	// Setup viewport
 	vp := viewport.New(80, 20)
 
-	// Determine CWD and File Path
-
-	cwd, err := os.Getwd()
+	// Determine the data file
+	targetFile, err := resolveDataFile(fileFlag)
 	if err != nil {
-		fmt.Printf("Error getting CWD: %v\n", err)
+		fmt.Printf("Error locating the data file: %v\n", err)
 		os.Exit(1)
 	}
-	targetFile := filepath.Join(cwd, ".tuido")
-
-	// Load Config
-	cfg, _ := core.LoadConfig()
-	author := cfg.Author
-	if author == "" {
-		// Ideally prompt user, but for now default to "User" or OS user
-		userEnv := os.Getenv("USER")
-		if userEnv == "" {
-			author = "User"
-		} else {
-			author = userEnv
-		}
-	}
 
 	m := model{
 		state:       stateViewMain,
 		filePath:    targetFile,
-		author:      author,
+		author:      resolveAuthor(),
 		textInput:   ti,
 		viewport:    vp,
+		list:        core.DefaultList,
 		entries:     []core.Entry{},
 		selectedIDs: make(map[string]struct{}),
+		collapsed:   make(map[string]bool),
+	}
+
+	// The workflow is needed to render the entries, so it is loaded first
+	cfg, err := core.LoadProjectConfig(targetFile)
+	m.workflow = cfg.Workflow
+	m.archiveDays = cfg.ArchiveDays
+	if err != nil {
+		m.msg = fmt.Sprintf("Using the default workflow: %v", err)
 	}
 
 	m.reloadEntries()
+	// Best effort: the dashboard just misses the project if this fails
+	core.RegisterProject(targetFile)
+
+	if !m.corrupt {
+		purged, err := core.PurgeExpiredTrash(targetFile, cfg.TrashDays)
+		if err != nil {
+			m.msg = fmt.Sprintf("Could not empty the trash: %v", err)
+		} else if purged > 0 {
+			m.reloadEntries()
+			m.msg = fmt.Sprintf("Purged %d entries that were in the trash for over %d days", purged, cfg.TrashDays)
+		}
+	}
 	return m
 }
 
+// resolveDataFile returns the path of the data file: the --file flag if set,
+// then $TUIDO_FILE, then the nearest existing data file in the current
+// directory or its parents. The search stops at the git repository root or
+// the home directory. Without one, a new file goes in the repository root,
+// or the current directory outside a repository.
+func resolveDataFile(fileFlag string) (string, error) {
+	if fileFlag != "" {
+		return filepath.Abs(fileFlag)
+	}
+	if env := os.Getenv("TUIDO_FILE"); env != "" {
+		return filepath.Abs(env)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	home, _ := os.UserHomeDir()
+	for dir := cwd; ; dir = filepath.Dir(dir) {
+		if path, ok := dataFileIn(dir); ok {
+			return path, nil
+		}
+		if fileExists(filepath.Join(dir, ".git")) {
+			return filepath.Join(dir, ".tuido"), nil
+		}
+		if dir == home || filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	return filepath.Join(cwd, ".tuido"), nil
+}
+
+// dataFileIn returns the data file in dir if there is one. A .tuido.jsonl
+// event log is used instead of .tuido if both exist.
+func dataFileIn(dir string) (string, bool) {
+	for _, name := range []string{".tuido.jsonl", ".tuido"} {
+		if path := filepath.Join(dir, name); fileExists(path) {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// parseFileFlag takes a leading --file path (or -file, --file=path) off the
+// command line
+func parseFileFlag(args []string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", args, nil
+	}
+	name, value, hasValue := strings.Cut(args[0], "=")
+	if name != "--file" && name != "-file" {
+		return "", args, nil
+	}
+	if hasValue {
+		return value, args[1:], nil
+	}
+	if len(args) < 2 {
+		return "", nil, fmt.Errorf("flag needs an argument: %s", name)
+	}
+	return args[1], args[2:], nil
+}
+
+// displayPath shortens a path inside the home directory to ~/...
+func displayPath(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.Join("~", rel)
+	}
+	return path
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// resolveAuthor returns the configured author, falling back to the OS user
+func resolveAuthor() string {
+	cfg, _ := core.LoadConfig()
+	if cfg.Author != "" {
+		return cfg.Author
+	}
+	// Ideally prompt user, but for now default to "User" or OS user
+	if userEnv := os.Getenv("USER"); userEnv != "" {
+		return userEnv
+	}
+	return "User"
+}
+
 func (m model) Init() tea.Cmd {
-	return textinput.Blink
+	return tea.Batch(textinput.Blink, waitForFileChange(m.changes))
+}
+
+// --- File Watching ---
+
+// fileChangedMsg is sent when the data file was modified on disk
+type fileChangedMsg struct{}
+
+func waitForFileChange(changes <-chan struct{}) tea.Cmd {
+	if changes == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		if _, ok := <-changes; !ok {
+			return nil
+		}
+		return fileChangedMsg{}
+	}
+}
+
+// applyExternalChange reloads entries modified by another process, keeping
+// the cursor, selection and scroll position anchored to entry IDs
+func (m *model) applyExternalChange() {
+	entries, err := core.LoadEntries(m.filePath)
+	if err != nil {
+		// Likely caught mid-write by a non-atomic writer; the next event retries
+		return
+	}
+	diff := core.DiffEntries(m.entries, entries)
+	if diff.Empty() {
+		// Our own save, or a write that changed nothing
+		return
+	}
+
+	var cursorID string
+	if m.state == stateBoardView {
+		if columns := m.boardColumns(); m.boardRow < len(columns[m.boardCol]) {
+			cursorID = columns[m.boardCol][m.boardRow].ID
+		}
+	} else if m.cursor < len(m.selectList) {
+		cursorID = m.selectList[m.cursor].ID
+	}
+	atBottom := m.viewport.AtBottom()
+	offset := m.viewport.YOffset
+
+	m.corrupt = false
+	m.entries = entries
+	m.base = slices.Clone(entries)
+	m.updateShortIDs()
+	m.msg = describeDiff(diff)
+
+	switch m.state {
+	case stateViewMain, stateFind:
+		m.updateViewport()
+		if !atBottom {
+			m.viewport.SetYOffset(offset)
+		}
+	case stateHistoryView:
+		m.viewport.SetContent(m.renderHistoryContent())
+		m.viewport.SetYOffset(offset)
+	case stateUpcomingView:
+		m.viewport.SetContent(m.renderUpcomingContent())
+		m.viewport.SetYOffset(offset)
+	case stateMineView:
+		m.viewport.SetContent(m.renderMineContent())
+		m.viewport.SetYOffset(offset)
+	case stateLogView:
+		m.viewport.SetContent(m.renderLogContent())
+		m.viewport.SetYOffset(offset)
+	case stateBoardView:
+		// Follow the focused card, wherever the other writer moved it
+		m.focusBoardCard(cursorID)
+	case stateSelectTask, stateEditTaskInput, stateConfirmDone:
+		m.selectList = m.selectionCandidates(m.selectionMode)
+		for id := range m.selectedIDs {
+			if _, ok := core.FindEntry(m.selectList, id); !ok {
+				delete(m.selectedIDs, id)
+			}
+		}
+		m.cursor = 0
+		found := false
+		for i, e := range m.selectList {
+			if e.ID == cursorID {
+				m.cursor = i
+				found = true
+				break
+			}
+		}
+		if m.state == stateEditTaskInput && !found {
+			m.textInput.SetValue("")
+			m.state = stateViewMain
+			m.updateViewport()
+			m.msg += " (the entry being edited is gone)"
+		} else if m.state == stateConfirmDone && !found {
+			m.state = stateViewMain
+			m.updateViewport()
+			m.msg += " (the task being completed is gone)"
+		} else if len(m.selectList) == 0 {
+			m.state = stateViewMain
+			m.updateViewport()
+		}
+	}
+}
+
+func describeDiff(diff core.EntryDiff) string {
+	var parts []string
+	if n := len(diff.Added); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d added", n))
+	}
+	if n := len(diff.Changed); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d updated", n))
+	}
+	if n := len(diff.Removed); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d removed", n))
+	}
+	return "Reloaded from disk: " + strings.Join(parts, ", ")
 }
 
 // --- Logic Helpers ---
 
 func (m *model) reloadEntries() {
 	entries, err := core.LoadEntries(m.filePath)
+	if errors.Is(err, core.ErrCorrupt) {
+		m.corrupt = true
+		m.msg = fmt.Sprintf("Error loading file: %v", err)
+		return
+	}
 	if err != nil {
 		m.msg = fmt.Sprintf("Error loading file: %v", err)
 		return
 	}
+	m.corrupt = false
 	m.entries = entries
+	m.base = slices.Clone(entries)
+	m.updateShortIDs()
 	m.updateViewport()
 }
 
 func (m *model) save() {
-	err := core.SaveEntries(m.filePath, m.entries)
-	if err != nil {
+	if m.corrupt {
+		// Saving now would replace the unreadable file with a partial list
+		m.msg = "Not saved: data file is corrupt, type /recover to restore the backup"
+		return
+	}
+	// Merge with the file on disk so changes made by other writers survive
+	_, err := core.SyncEntries(m.filePath, m.base, m.entries)
+	if errors.Is(err, core.ErrHistory) {
+		m.msg = fmt.Sprintf("Saved, but %v", err)
+	} else if err != nil {
 		m.msg = fmt.Sprintf("Error saving file: %v", err)
 	}
 	m.reloadEntries()
 }
 
+// undo reverts the last change, or with redo re-applies the last undone one
+func (m *model) undo(redo bool) {
+	if m.corrupt {
+		m.msg = "Data file is corrupt, type /recover to restore the backup"
+		return
+	}
+	op, err := core.UndoEntries(m.filePath, redo)
+	if err != nil {
+		m.msg = err.Error()
+		return
+	}
+	m.reloadEntries()
+	if redo {
+		m.msg = "Redid " + op.Describe()
+	} else {
+		m.msg = "Undid " + op.Describe() + " (ctrl+y to redo)"
+	}
+}
+
+// listEntries returns the entries on the current list
+func (m model) listEntries() []core.Entry {
+	return core.FilterByList(m.entries, m.list)
+}
+
+// addEntry adds a note or todo to the current list
+func (m *model) addEntry(text string, entryType core.EntryType) {
+	m.entries = core.AddEntry(m.entries, text, m.author, entryType)
+	id := m.entries[len(m.entries)-1].ID
+	m.entries = core.MoveToList(m.entries, map[string]struct{}{id: {}}, m.list)
+}
+
+// listTabs returns the lists shown in the tab bar: those holding entries,
+// plus the current one while it is still empty
+func (m model) listTabs() []string {
+	lists := core.ListNames(m.entries)
+	if !slices.Contains(lists, m.list) {
+		lists = append(lists, m.list)
+	}
+	return lists
+}
+
+// switchList shows another list
+func (m *model) switchList(name string) {
+	m.list = name
+	m.query = ""
+	m.tagFilter = ""
+	m.msg = "Switched to list " + name
+	if len(core.GetActiveItems(m.listEntries())) == 0 {
+		m.msg += "; it is empty, new entries go here"
+	}
+	m.updateViewport()
+}
+
+// loadArchive reads the archive on first use and recomputes the short IDs
+// over the live and archived entries, so they are unique across both
+func (m *model) loadArchive() bool {
+	if m.archive == nil {
+		archive, err := core.LoadArchive(m.filePath)
+		if err != nil {
+			m.msg = fmt.Sprintf("Could not load the archive: %v", err)
+			return false
+		}
+		m.archive = archive
+	}
+	m.shortIDs = core.ShortIDs(core.WithArchive(m.entries, m.archive))
+	return true
+}
+
+// withArchive returns entries followed by the archived ones on the current list
+func (m *model) withArchive(entries []core.Entry) []core.Entry {
+	if !m.loadArchive() {
+		return entries
+	}
+	return core.WithArchive(entries, core.FilterByList(m.archive, m.list))
+}
+
+// updateShortIDs recomputes the short IDs after the entries changed. While
+// the history shows the archive, archived entries keep theirs.
+func (m *model) updateShortIDs() {
+	m.shortIDs = core.ShortIDs(m.entries)
+	if m.histArchive {
+		m.loadArchive()
+	}
+}
+
+// archiveCompleted moves the todos completed more than days ago to the archive
+func (m *model) archiveCompleted(days int) {
+	keep, archived := core.SplitArchivable(m.entries, time.Now().AddDate(0, 0, -days))
+	if len(archived) == 0 {
+		m.msg = fmt.Sprintf("Nothing to archive: no todos were completed over %d days ago", days)
+		return
+	}
+	if m.corrupt {
+		m.msg = "Not archived: data file is corrupt, type /recover to restore the backup"
+		return
+	}
+	if err := core.WriteArchive(m.filePath, archived); err != nil {
+		m.msg = fmt.Sprintf("Could not write the archive: %v", err)
+		return
+	}
+	m.archive = nil
+	m.entries = keep
+	m.msg = ""
+	m.save()
+	if m.msg == "" {
+		m.msg = fmt.Sprintf("Archived %d todos to %s, /dhist archive lists them", len(archived), filepath.Base(core.ArchiveDir(m.filePath)))
+	}
+}
+
+// entryGroup is a titled section of the main view
+type entryGroup struct {
+	name    string
+	entries []core.Entry
+}
+
 func (m *model) updateViewport() {
 	var sb strings.Builder
 
+	entries := core.LiveEntries(m.listEntries())
+	if m.tagFilter != "" {
+		entries = core.FilterByTag(entries, m.tagFilter)
+	}
+
+	if m.query != "" {
+		// Search results cover the whole history, best match first
+		if q, err := core.ParseQuery(m.query); err == nil && q.IncludesArchive() {
+			entries = m.withArchive(entries)
+		}
+		matches, err := m.searchMatches(entries)
+		var qe *core.QueryError
+		if errors.As(err, &qe) {
+			sb.WriteString(cRed.Render(qe.Caret()) + "\n" + cRed.Render(qe.Msg) + "\n")
+		}
+		for _, match := range matches {
+			line := m.renderEntryLine(highlightMatch(match))
+			if match.Entry.ArchivedAt != nil {
+				line += cGray.Render(" (archived)")
+			}
+			sb.WriteString(line + "\n")
+		}
+		m.viewport.SetContent(sb.String())
+		m.viewport.GotoTop()
+		return
+	}
+
+	// Most important first; equal priorities keep their chronological order
+	var visible []core.Entry
+	for _, e := range core.SortByPriority(entries) {
+		if e.Type == core.TypeTodo && e.CompletedAt != nil {
+			// Skip completed tasks in main view
+			continue
+		}
+		visible = append(visible, e)
+	}
+
+	// Notes first, then open todos grouped by workflow state
+	var notes []core.Entry
+	for _, e := range visible {
+		if e.Type == core.TypeNote {
+			notes = append(notes, e)
+		}
+	}
+	groups := []entryGroup{{"notes", notes}}
+	for _, state := range m.workflow.States {
+		if !state.Terminal {
+			groups = append(groups, entryGroup{state.Name, core.FilterByStatus(visible, state.Name, m.workflow)})
+		}
+	}
+
+	for _, g := range groups {
+		if len(g.entries) == 0 {
+			continue
+		}
+		sb.WriteString(cBlue.Render(fmt.Sprintf("── %s (%d)", strings.ToUpper(g.name), len(g.entries))) + "\n")
+		// Subtasks follow their parent, indented one level per generation
+		for _, item := range core.FlattenTree(g.entries, m.collapsed) {
+			sb.WriteString(m.renderTreePrefix(item) + m.renderEntryLine(item.Entry) + "\n")
+		}
+	}
+
+	m.viewport.SetContent(sb.String())
+	m.viewport.GotoBottom()
+}
+
+// renderEntryLine formats a note or open todo for the main view
+func (m model) renderEntryLine(e core.Entry) string {
 	// Helper to format date
 	fmtDate := func(t time.Time) string {
 		return t.Format("02-01-2006 15:04")
 	}
 
-	for _, e := range m.entries {
-		line := ""
-		if e.Type == core.TypeNote {
-			// [ Author, datetime ] - COMMENT TEXT
-			line = fmt.Sprintf("[ %s, %s ] - %s",
-				cMagenta.Render(e.Author),
-				cYellow.Render(fmtDate(e.CreatedAt)),
-				e.Text)
-		} else if e.Type == core.TypeTodo {
-			if e.CompletedAt == nil {
-				// [ TODO ] - [ Author, created_at ] - TASK TEXT
-				line = fmt.Sprintf("[ %s ] - [ %s, %s ] - %s",
-					cGreen.Render("TODO"),
-					cMagenta.Render(e.Author),
-					cYellow.Render(fmtDate(e.CreatedAt)),
-					e.Text)
-			} else {
-				// Skip completed tasks in main view
-				continue
-			}
+	if e.Type == core.TypeNote {
+		// ID [ Author, datetime ] - COMMENT TEXT #tags
+		return fmt.Sprintf("%s [ %s, %s ] - %s%s",
+			cGray.Render(m.shortIDs[e.ID]),
+			cMagenta.Render(e.CreatedBy),
+			cYellow.Render(fmtDate(e.CreatedAt)),
+			e.Text,
+			renderTags(e.Tags))
+	}
+
+	// ID [ TODO !priority ] - [ Author -> Assignees, created_at ] - TASK TEXT #tags due
+	status := cGreen.Render(strings.ToUpper(core.StatusOf(e, m.workflow)))
+	created := cYellow.Render(fmtDate(e.CreatedAt))
+	text, blocked := e.Text, ""
+	if blockers := core.OpenBlockers(m.entries, e); len(blockers) > 0 {
+		// Dim the task, it cannot be started yet
+		status = cGray.Render(strings.ToUpper(core.StatusOf(e, m.workflow)))
+		created = cGray.Render(fmtDate(e.CreatedAt))
+		text = cGray.Render(e.Text)
+		blocked = cGray.Render(" (blocked by " + shortRefs(m.shortIDs, blockers) + ")")
+	}
+	return fmt.Sprintf("%s [ %s%s ] - [ %s, %s ] - %s%s%s%s",
+		cGray.Render(m.shortIDs[e.ID]),
+		status,
+		renderPriority(e.Priority),
+		renderPeople(e),
+		created,
+		text,
+		renderTags(e.Tags),
+		renderDue(e, time.Now()),
+		blocked)
+}
+
+// searchMatches applies the search query to entries. Structured terms such as
+// tag:api or -done filter exactly; the remaining words are matched fuzzily.
+func (m model) searchMatches(entries []core.Entry) ([]core.FuzzyMatch, error) {
+	q, err := core.ParseQuery(m.query)
+	if err != nil {
+		return nil, err
+	}
+	ctx := core.QueryContext{Entries: m.entries, Workflow: m.workflow, Now: time.Now()}
+	return core.FuzzyFind(q.WithoutText().Filter(entries, ctx), q.Text()), nil
+}
+
+// highlightMatch returns the matched entry with the matched characters styled.
+// The entry is a copy for rendering only, so it never reaches the data file.
+func highlightMatch(match core.FuzzyMatch) core.Entry {
+	e := match.Entry
+	styled := highlight(match.Value, match.Positions)
+	switch match.Field {
+	case "text":
+		e.Text = styled
+	case "created_by":
+		e.CreatedBy = styled
+	case "assignees":
+		e.Assignees = slices.Clone(e.Assignees)
+		e.Assignees[slices.Index(e.Assignees, match.Value)] = styled
+	case "tags":
+		e.Tags = slices.Clone(e.Tags)
+		e.Tags[slices.Index(e.Tags, match.Value)] = styled
+	}
+	return e
+}
+
+// highlight styles the runes of s at the given offsets
+func highlight(s string, positions []int) string {
+	var sb strings.Builder
+	next := 0
+	for i, r := range []rune(s) {
+		if next < len(positions) && positions[next] == i {
+			sb.WriteString(cMatch.Render(string(r)))
+			next++
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// completeTodos moves todos to the workflow's done state. If the workflow does
+// not allow that for one of them, none are completed.
+func (m *model) completeTodos(ids ...string) {
+	entries := slices.Clone(m.entries)
+	for _, id := range ids {
+		var err error
+		if entries, err = core.CompleteTodo(entries, id, m.author, m.workflow); err != nil {
+			m.msg = fmt.Sprintf("Not completed: %v", err)
+			return
+		}
+	}
+	m.entries = entries
+}
+
+// blockedWarning returns a warning if e still waits on open blockers
+func (m model) blockedWarning(e core.Entry) string {
+	blockers := core.OpenBlockers(m.entries, e)
+	if len(blockers) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Warning: %s was still blocked by %s", m.shortIDs[e.ID], shortRefs(m.shortIDs, blockers))
+}
+
+// renderTreePrefix indents a subtask and marks parents as expanded or collapsed
+func (m model) renderTreePrefix(item core.TreeItem) string {
+	indent := strings.Repeat("  ", item.Depth)
+	switch {
+	case item.HasChildren && m.collapsed[item.Entry.ID]:
+		hidden := len(core.GetOpenSubtasks(m.entries, item.Entry.ID))
+		return indent + cGray.Render(fmt.Sprintf("▸ (+%d) ", hidden))
+	case item.HasChildren:
+		return indent + cGray.Render("▾ ")
+	case item.Depth > 0:
+		return indent + cGray.Render("└ ")
+	}
+	return indent
+}
+
+// renderPeople shows who created a todo and, if someone else, who it is assigned to
+func renderPeople(e core.Entry) string {
+	creator, assignees, ok := strings.Cut(core.FormatPeople(e), " -> ")
+	if !ok {
+		return cMagenta.Render(creator)
+	}
+	return cMagenta.Render(creator) + cGray.Render(" -> ") + cCyan.Render(assignees)
+}
+
+func (m model) renderMineContent() string {
+	var sb strings.Builder
+	for _, e := range core.SortByPriority(core.GetAssignedTodos(m.listEntries(), m.author)) {
+		sb.WriteString(m.renderEntryLine(e) + "\n")
+	}
+	if sb.Len() == 0 {
+		return cGray.Render("Nothing assigned to " + m.author + ".")
+	}
+	return sb.String()
+}
+
+// renderTags returns the entry's tags as #hashtags, or nothing without tags
+func renderTags(tags []string) string {
+	var sb strings.Builder
+	for _, t := range tags {
+		sb.WriteString(" " + cBlue.Render("#"+t))
+	}
+	return sb.String()
+}
+
+// renderPriority returns a color-coded priority marker, or nothing for no priority
+func renderPriority(p core.Priority) string {
+	switch p {
+	case core.PriorityUrgent:
+		return " " + cUrgent.Render("!urgent")
+	case core.PriorityHigh:
+		return " " + cRed.Render("!high")
+	case core.PriorityMedium:
+		return " " + cYellow.Render("!medium")
+	case core.PriorityLow:
+		return " " + cBlue.Render("!low")
+	}
+	return ""
+}
+
+// renderDue returns a due date marker styled by urgency, or nothing without a due date
+func renderDue(e core.Entry, now time.Time) string {
+	if e.DueAt == nil {
+		return ""
+	}
+	date := e.DueAt.Format("02-01-2006")
+	switch core.GetDueState(e, now) {
+	case core.DueOverdue:
+		return " " + cRed.Bold(true).Render("OVERDUE "+date)
+	case core.DueToday:
+		return " " + cYellow.Bold(true).Render("DUE TODAY")
+	}
+	return " " + cGray.Render("due "+date)
+}
+
+func (m model) renderUpcomingContent() string {
+	var sb strings.Builder
+	now := time.Now()
+
+	for _, e := range core.GetUpcomingTodos(m.listEntries()) {
+		// ID [ due ] - [ Author ] - TASK TEXT
+		due := e.DueAt.Format("Mon 02-01-2006")
+		switch core.GetDueState(e, now) {
+		case core.DueOverdue:
+			due = cRed.Render(due)
+		case core.DueToday:
+			due = cYellow.Render(due)
+		default:
+			due = cCyan.Render(due)
 		}
+		line := fmt.Sprintf("%s [ %s ] - [ %s%s ] - %s",
+			cGray.Render(m.shortIDs[e.ID]),
+			due,
+			renderPeople(e),
+			renderPriority(e.Priority),
+			e.Text)
 		sb.WriteString(line + "\n")
 	}
+	if sb.Len() == 0 {
+		return cGray.Render("No open todos with a due date.")
+	}
+	return sb.String()
+}
 
-	m.viewport.SetContent(sb.String())
-	m.viewport.GotoBottom()
+// renderLogContent lists the revisions of the entry picked with /log
+func (m model) renderLogContent() string {
+	e, ok := core.FindEntry(m.entries, m.logID)
+	if !ok {
+		return cGray.Render("The entry was removed.")
+	}
+	var sb strings.Builder
+	for _, r := range core.Timeline(e) {
+		sb.WriteString(fmt.Sprintf("%s  %s  %s\n",
+			cYellow.Render(r.At.Format("02-01-2006 15:04")),
+			cBlue.Render(r.By),
+			r.Describe()))
+	}
+	return sb.String()
 }
 
 func (m model) renderHistoryContent() string {
@@ -182,14 +841,22 @@ func (m model) renderHistoryContent() string {
 		return t.Format("02-01-2006 15:04")
 	}
 
-	completed := core.GetCompletedTodos(m.entries)
+	entries := m.listEntries()
+	if m.histArchive {
+		entries = core.WithArchive(entries, core.FilterByList(m.archive, m.list))
+	}
+	completed := core.GetCompletedTodos(entries)
 	for _, e := range completed {
-		line := fmt.Sprintf("[ %s ] - [ %s, %s -> %s ] - %s",
-			cCyan.Render("DONE"),
-			cMagenta.Render(e.Author),
+		line := fmt.Sprintf("%s [ %s ] - [ %s, %s -> %s ] - %s",
+			cGray.Render(m.shortIDs[e.ID]),
+			cCyan.Render(strings.ToUpper(core.StatusOf(e, m.workflow))),
+			renderPeople(e),
 			cYellow.Render(fmtDate(e.CreatedAt)),
 			cYellow.Render(fmtDate(*e.CompletedAt)),
 			e.Text)
+		if e.ArchivedAt != nil {
+			line += cGray.Render(" (archived)")
+		}
 		sb.WriteString(line + "\n")
 	}
 	return sb.String()
@@ -203,7 +870,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.String() == "ctrl+c" {
 			return m, tea.Quit
 		}
+	case fileChangedMsg:
+		m.applyExternalChange()
+		return m, waitForFileChange(m.changes)
 	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
 		m.viewport.Width = msg.Width
 		// Static height: TopPadding(2) + Title(6) + Author(1) + Gap(1) + Input(1) + Help(1) = 12
 		// Let's use 14 to be safe and avoid scrolling issues.
@@ -220,8 +891,14 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateTaskSelect(msg)
 	case stateEditTaskInput:
 		return m.updateEditTask(msg)
-	case stateHistoryView:
+	case stateHistoryView, stateUpcomingView, stateMineView, stateLogView:
 		return m.updateHistory(msg)
+	case stateConfirmDone:
+		return m.updateConfirmDone(msg)
+	case stateBoardView:
+		return m.updateBoard(msg)
+	case stateFind:
+		return m.updateFind(msg)
 	}
 
 	return m, nil
@@ -251,18 +928,173 @@ func (m model) updateMain(msg tea.Msg) (tea.Model, tea.Cmd) {
 						text = strings.TrimPrefix(val, "/t ")
 					}
 					if text != "" {
-						m.entries = core.AddEntry(m.entries, text, m.author, core.TypeTodo)
+						m.addEntry(text, core.TypeTodo)
 						m.save()
 					}
+				case "/sub", "/s":
+					parts := strings.Fields(val)
+					if len(parts) < 2 {
+						m.msg = "Usage: /sub [text], then pick the parent task"
+						break
+					}
+					m.pendingText = strings.Join(parts[1:], " ")
+					m.prepareTaskSelection(modeSubtask)
+				case "/fold", "/f":
+					if strings.TrimSpace(strings.TrimPrefix(val, cmdStr)) == "all" {
+						for _, item := range core.FlattenTree(core.LiveEntries(m.listEntries()), nil) {
+							if item.HasChildren {
+								m.collapsed[item.Entry.ID] = true
+							}
+						}
+						m.updateViewport()
+						break
+					}
+					m.prepareTaskSelection(modeFold)
+				case "/unfold":
+					clear(m.collapsed)
+					m.updateViewport()
 				case "/done", "/d":
 					m.prepareTaskSelection(modeDone)
+				case "/", "/find":
+					if q := strings.TrimSpace(strings.TrimPrefix(val, cmdStr)); q != "" {
+						m.query = q
+					}
+					m.state = stateFind
+					m.textInput.SetValue(m.query)
+					m.textInput.CursorEnd()
+					m.updateViewport()
+					return m, nil
+				case "/undo":
+					m.undo(false)
+				case "/redo":
+					m.undo(true)
+				case "/board":
+					m.state = stateBoardView
+					m.clampBoardCursor()
+				case "/status":
+					parts := strings.Fields(val)
+					var names []string
+					for _, s := range m.workflow.States {
+						names = append(names, s.Name)
+					}
+					if len(parts) < 2 {
+						m.msg = "Usage: /status <state>. States: " + strings.Join(names, ", ")
+						break
+					}
+					status := core.NormalizeStatus(strings.Join(parts[1:], " "))
+					if _, ok := m.workflow.State(status); !ok {
+						m.msg = fmt.Sprintf("Unknown state %q. States: %s", status, strings.Join(names, ", "))
+						break
+					}
+					m.pendingStatus = status
+					m.prepareTaskSelection(modeStatus)
+				case "/block", "/b":
+					m.prepareTaskSelection(modeBlock)
+				case "/unblock":
+					m.prepareTaskSelection(modeUnblock)
 				case "/undone":
 					m.prepareTaskSelection(modeUndone)
 				case "/rm":
 					m.prepareTaskSelection(modeRemove)
+				case "/list", "/l":
+					parts := strings.Fields(val)
+					if len(parts) < 2 {
+						m.msg = "Lists: " + strings.Join(m.listTabs(), ", ") + " (/list <name> to switch or create, Tab for the next)"
+						break
+					}
+					name, ok := core.NormalizeList(parts[1])
+					if !ok {
+						m.msg = "List names start with a letter and use letters, digits, - and _"
+						break
+					}
+					m.switchList(name)
+				case "/move", "/mv":
+					parts := strings.Fields(val)
+					name, ok := "", false
+					if len(parts) == 2 {
+						name, ok = core.NormalizeList(parts[1])
+					}
+					if !ok {
+						m.msg = "Usage: /move <list>, then pick the entries"
+						break
+					}
+					if name == m.list {
+						m.msg = "The entries are already on " + name
+						break
+					}
+					m.pendingList = name
+					m.prepareTaskSelection(modeMove)
+				case "/trash":
+					m.prepareTaskSelection(modeTrash)
+					if len(m.selectList) == 0 {
+						m.msg = "The trash is empty"
+					}
 				case "/edit", "/e":
 					m.prepareTaskSelection(modeEdit)
+				case "/log":
+					m.prepareTaskSelection(modeLog)
+				case "/priority", "/p":
+					parts := strings.Fields(val)
+					if len(parts) < 2 {
+						m.msg = "Usage: /priority high|medium|low|none"
+						break
+					}
+					level, ok := core.ParsePriority(parts[1])
+					if !ok {
+						m.msg = fmt.Sprintf("Unknown priority %q, use urgent, high, medium, low or none", parts[1])
+						break
+					}
+					m.pendingPrio = level
+					m.prepareTaskSelection(modePriority)
+				case "/due":
+					parts := strings.Fields(val)
+					if len(parts) < 2 {
+						m.msg = "Usage: /due today|friday|+3d|2026-11-01|none"
+						break
+					}
+					m.pendingDue = nil
+					if expr := strings.Join(parts[1:], " "); expr != "none" && expr != "clear" {
+						due, err := core.ParseDue(expr, time.Now())
+						if err != nil {
+							m.msg = err.Error()
+							break
+						}
+						m.pendingDue = &due
+					}
+					m.prepareTaskSelection(modeDue)
+				case "/tag":
+					parts := strings.Fields(val)
+					if len(parts) < 2 {
+						m.tagFilter = ""
+						m.updateViewport()
+						if tags := core.AllTags(m.listEntries()); len(tags) > 0 {
+							m.msg = "Filter cleared. Tags: #" + strings.Join(tags, " #")
+						} else {
+							m.msg = "Filter cleared. No tags yet, add #tag to an entry"
+						}
+						break
+					}
+					tag, ok := core.NormalizeTag(parts[1])
+					if !ok {
+						m.msg = fmt.Sprintf("Invalid tag %q", parts[1])
+						break
+					}
+					m.tagFilter = tag
+					m.updateViewport()
+				case "/mine", "/m":
+					m.state = stateMineView
+					m.viewport.SetContent(m.renderMineContent())
+					m.viewport.GotoTop()
+				case "/upcoming", "/u":
+					m.state = stateUpcomingView
+					m.viewport.SetContent(m.renderUpcomingContent())
+					m.viewport.GotoTop()
 				case "/dhist":
+					parts := strings.Fields(val)
+					m.histArchive = len(parts) > 1 && parts[1] == "archive"
+					if m.histArchive {
+						m.loadArchive()
+					}
 					m.state = stateHistoryView
 					m.viewport.SetContent(m.renderHistoryContent())
 					m.viewport.GotoBottom()
@@ -274,26 +1106,65 @@ func (m model) updateMain(msg tea.Msg) (tea.Model, tea.Cmd) {
 						core.SaveConfig(core.Config{Author: name})
 						m.msg = "Author updated to " + name
 					}
+				case "/archive":
+					days := m.archiveDays
+					if parts := strings.Fields(val); len(parts) > 1 {
+						n, err := strconv.Atoi(parts[1])
+						if err != nil || n < 0 {
+							m.msg = "Usage: /archive [days]"
+							break
+						}
+						days = n
+					}
+					m.archiveCompleted(days)
 				case "/export":
 					ts := time.Now().Format("20060102_150405")
 					filename := fmt.Sprintf("todo_%s.md", ts)
-					content := core.GenerateExportMarkdown(m.entries)
+					// The current list, or every list with /export all
+					content := core.GenerateExportMarkdown(m.listEntries())
+					if parts := strings.Fields(val); len(parts) > 1 && parts[1] == "all" {
+						content = core.GenerateListsExportMarkdown(m.entries)
+					}
 					err := os.WriteFile(filename, []byte(content), 0o644)
 					if err != nil {
 						m.msg = fmt.Sprintf("Export failed: %v", err)
 					} else {
 						m.msg = fmt.Sprintf("Exported to %s", filename)
 					}
+				case "/recover":
+					if _, err := core.RestoreBackup(m.filePath); err != nil {
+						m.msg = fmt.Sprintf("Recovery failed: %v", err)
+					} else {
+						m.reloadEntries()
+						m.msg = "Restored from " + filepath.Base(core.BackupPath(m.filePath))
+					}
 				case "/help":
-					m.msg = "Commands: /todo, /done, /undone, /rm, /edit, /dhist, /author, /export, /exit"
+					m.msg = "Commands: /todo, /sub, /fold, /unfold, /done, /find, /undo, /redo, /status, /board, /block, /unblock, /undone, /rm, /trash, /edit, /log, /priority, /due, /upcoming, /mine, /tag, /dhist, /archive, /list, /move, /author, /export, /recover, /exit"
 				}
 			} else if val != "" {
 				// Regular Note
-				m.entries = core.AddEntry(m.entries, val, m.author, core.TypeNote)
+				m.addEntry(val, core.TypeNote)
 				m.save()
 			}
 			return m, nil
 
+		case tea.KeyTab:
+			tabs := m.listTabs()
+			m.switchList(tabs[(slices.Index(tabs, m.list)+1)%len(tabs)])
+			return m, nil
+
+		case tea.KeyEsc:
+			if m.query != "" {
+				m.query = ""
+				m.updateViewport()
+			}
+		case tea.KeyCtrlZ:
+			m.undo(false)
+			return m, nil
+		case tea.KeyCtrlY:
+			m.undo(true)
+			return m, nil
+
 		// Viewport Scrolling
 		case tea.KeyUp, tea.KeyPgUp:
 			m.viewport.ScrollUp(1)
@@ -306,29 +1177,137 @@ func (m model) updateMain(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// updateFind live-filters the main view as the search query is typed
+func (m model) updateFind(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyEsc:
+			m.query = ""
+			m.textInput.SetValue("")
+			m.state = stateViewMain
+			m.updateViewport()
+			return m, nil
+		case tea.KeyEnter:
+			// Keep the results so /done, /edit and /rm act on them
+			m.query = strings.TrimSpace(m.textInput.Value())
+			matches, err := m.searchMatches(core.LiveEntries(m.listEntries()))
+			if err != nil {
+				m.msg = "Invalid query: " + err.Error()
+				return m, nil
+			}
+			m.textInput.SetValue("")
+			m.state = stateViewMain
+			m.updateViewport()
+			if m.query != "" {
+				m.msg = fmt.Sprintf("%d matches. /done, /edit and /rm now act on them, Esc clears the search", len(matches))
+			}
+			return m, nil
+		case tea.KeyUp, tea.KeyPgUp:
+			m.viewport.ScrollUp(1)
+			return m, nil
+		case tea.KeyDown, tea.KeyPgDown:
+			m.viewport.ScrollDown(1)
+			return m, nil
+		}
+	}
+
+	m.textInput, cmd = m.textInput.Update(msg)
+	if q := strings.TrimSpace(m.textInput.Value()); q != m.query {
+		m.query = q
+		m.updateViewport()
+	}
+	return m, cmd
+}
+
 func (m model) updateHistory(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg.(type) {
 	case tea.KeyMsg:
 		// Any key returns to main
 		m.state = stateViewMain
+		m.histArchive = false
+		m.updateShortIDs()
 		m.updateViewport()
 	}
 	return m, nil
 }
 
-func (m *model) prepareTaskSelection(mode selectMode) {
-	m.selectList = []core.Entry{}
-	m.selectionMode = mode
-	m.selectedIDs = make(map[string]struct{})
-
+// selectionCandidates returns the entries a selection mode operates on, in tree order
+func (m model) selectionCandidates(mode selectMode) []core.Entry {
+	entries := m.listEntries()
+	var candidates []core.Entry
 	switch mode {
-	case modeDone:
-		m.selectList = core.GetActiveTodos(m.entries)
+	case modeDone, modeDue, modeSubtask, modeBlock:
+		candidates = core.GetActiveTodos(entries)
+	case modeBlocker:
+		for _, e := range core.GetActiveTodos(entries) {
+			if e.ID != m.pendingBlock {
+				candidates = append(candidates, e)
+			}
+		}
+	case modeStatus:
+		// Only todos that may move to the pending state, including reopening completed ones
+		for _, e := range core.LiveEntries(entries) {
+			if e.Type == core.TypeTodo && m.workflow.CanTransition(core.StatusOf(e, m.workflow), m.pendingStatus) {
+				candidates = append(candidates, e)
+			}
+		}
+	case modeUnblock:
+		for _, e := range core.GetActiveTodos(entries) {
+			if len(e.BlockedBy) > 0 {
+				candidates = append(candidates, e)
+			}
+		}
 	case modeUndone:
-		m.selectList = core.GetCompletedTodos(m.entries)
+		candidates = core.GetCompletedTodos(entries)
 	case modeRemove, modeEdit:
-		m.selectList = core.GetActiveItems(m.entries)
+		candidates = core.GetActiveItems(entries)
+	case modeMove:
+		// Subtasks move with their parent
+		for _, e := range core.GetActiveItems(entries) {
+			if _, ok := core.FindEntry(entries, e.ParentID); !ok {
+				candidates = append(candidates, e)
+			}
+		}
+	case modeLog:
+		candidates = core.LiveEntries(entries)
+	case modeTrash:
+		// Most recently removed first, like a stack, regardless of search and tree
+		return core.GetTrashedEntries(entries)
+	case modePriority:
+		candidates = core.SortByPriority(core.GetActiveTodos(entries))
+	case modeFold:
+		// Only parents with something to hide
+		for _, item := range core.FlattenTree(core.GetActiveItems(entries), nil) {
+			if item.HasChildren {
+				candidates = append(candidates, item.Entry)
+			}
+		}
+		return candidates
+	}
+
+	// An active search narrows the list to its matches, except when picking
+	// a second task that relates to the one already chosen
+	if m.query != "" && mode != modeBlocker && mode != modeSubtask {
+		matched := make(map[string]bool)
+		matches, _ := m.searchMatches(candidates)
+		for _, match := range matches {
+			matched[match.Entry.ID] = true
+		}
+		candidates = slices.DeleteFunc(candidates, func(e core.Entry) bool { return !matched[e.ID] })
+	}
+
+	ordered := make([]core.Entry, 0, len(candidates))
+	for _, item := range core.FlattenTree(candidates, nil) {
+		ordered = append(ordered, item.Entry)
 	}
+	return ordered
+}
+
+func (m *model) prepareTaskSelection(mode selectMode) {
+	m.selectionMode = mode
+	m.selectedIDs = make(map[string]struct{})
+	m.selectList = m.selectionCandidates(mode)
 
 	if len(m.selectList) == 0 {
 		m.msg = "Nothing to select!"
@@ -338,6 +1317,14 @@ func (m *model) prepareTaskSelection(mode selectMode) {
 	m.cursor = 0
 }
 
+// markedIDs returns the entries toggled with space, or the one under the cursor if none are
+func (m model) markedIDs() map[string]struct{} {
+	if len(m.selectedIDs) > 0 {
+		return m.selectedIDs
+	}
+	return map[string]struct{}{m.selectList[m.cursor].ID: {}}
+}
+
 func (m model) updateTaskSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -351,7 +1338,7 @@ func (m model) updateTaskSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.cursor++
 			}
 		case " ":
-			if m.selectionMode == modeRemove {
+			if m.multiselect() {
 				id := m.selectList[m.cursor].ID
 				if _, selected := m.selectedIDs[id]; selected {
 					delete(m.selectedIDs, id)
@@ -359,23 +1346,100 @@ func (m model) updateTaskSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.selectedIDs[id] = struct{}{}
 				}
 			}
+		case "D":
+			if m.selectionMode != modeTrash {
+				break
+			}
+			// Permanently delete the selected entries, or the one under the cursor
+			ids := m.markedIDs()
+			m.entries = core.RemoveEntries(m.entries, ids)
+			m.save()
+			if m.msg == "" {
+				m.msg = fmt.Sprintf("Permanently deleted %d entries", len(ids))
+			}
+			m.state = stateViewMain
 		case "enter":
 			if m.selectionMode == modeRemove {
-				// If nothing is selected via space, remove the item under cursor
-				if len(m.selectedIDs) == 0 {
-					m.entries = core.RemoveEntry(m.entries, m.selectList[m.cursor].ID)
-				} else {
-					m.entries = core.RemoveEntries(m.entries, m.selectedIDs)
-				}
+				ids := m.markedIDs()
+				m.entries = core.TrashEntries(m.entries, ids, m.author)
+				m.msg = fmt.Sprintf("Moved %d entries to the trash, /trash to restore them", len(ids))
+			} else if m.selectionMode == modeTrash {
+				ids := m.markedIDs()
+				m.entries = core.RestoreEntries(m.entries, ids, m.author)
+				m.msg = fmt.Sprintf("Restored %d entries", len(ids))
+			} else if m.selectionMode == modeMove {
+				ids := m.markedIDs()
+				m.entries = core.MoveToList(m.entries, ids, m.pendingList)
+				m.msg = fmt.Sprintf("Moved %d entries to %s", len(ids), m.pendingList)
 			} else {
 				selected := m.selectList[m.cursor]
 				if m.selectionMode == modeDone {
-					m.entries = core.MarkDone(m.entries, selected.ID)
+					if len(core.GetOpenSubtasks(m.entries, selected.ID)) > 0 {
+						// Ask before leaving subtasks open under a finished parent
+						m.state = stateConfirmDone
+						return m, nil
+					}
+					m.msg = m.blockedWarning(selected)
+					m.completeTodos(selected.ID)
+				} else if m.selectionMode == modeBlock {
+					m.pendingBlock = selected.ID
+					m.prepareTaskSelection(modeBlocker)
+					if len(m.selectList) == 0 {
+						m.state = stateViewMain
+						m.msg = "No other open task to block it on"
+					}
+					return m, nil
+				} else if m.selectionMode == modeBlocker {
+					var err error
+					if m.entries, err = core.AddBlocker(m.entries, m.pendingBlock, selected.ID); err != nil {
+						m.msg = err.Error()
+					}
+				} else if m.selectionMode == modeStatus {
+					if state, _ := m.workflow.State(m.pendingStatus); state.Terminal {
+						m.msg = m.blockedWarning(selected)
+					}
+					var err error
+					if m.entries, err = core.SetStatus(m.entries, selected.ID, m.pendingStatus, m.author, m.workflow); err != nil {
+						m.msg = err.Error()
+					}
+				} else if m.selectionMode == modeUnblock {
+					m.entries = core.RemoveBlocker(m.entries, selected.ID, "")
+				} else if m.selectionMode == modeSubtask {
+					m.addEntry(m.pendingText, core.TypeTodo)
+					var err error
+					m.entries, err = core.SetParent(m.entries, m.entries[len(m.entries)-1].ID, selected.ID)
+					if err != nil {
+						m.msg = err.Error()
+					}
+					delete(m.collapsed, selected.ID)
+				} else if m.selectionMode == modeFold {
+					if m.collapsed[selected.ID] {
+						delete(m.collapsed, selected.ID)
+					} else {
+						m.collapsed[selected.ID] = true
+					}
+					m.state = stateViewMain
+					m.updateViewport()
+					return m, nil
 				} else if m.selectionMode == modeUndone {
-					m.entries = core.MarkUndone(m.entries, selected.ID)
+					if entries, err := core.ReopenTodo(m.entries, selected.ID, m.author, m.workflow); err != nil {
+						m.msg = fmt.Sprintf("Not reopened: %v", err)
+					} else {
+						m.entries = entries
+					}
+				} else if m.selectionMode == modePriority {
+					m.entries = core.SetPriority(m.entries, selected.ID, m.pendingPrio)
+				} else if m.selectionMode == modeDue {
+					m.entries = core.SetDue(m.entries, selected.ID, m.pendingDue)
+				} else if m.selectionMode == modeLog {
+					m.logID = selected.ID
+					m.state = stateLogView
+					m.viewport.SetContent(m.renderLogContent())
+					m.viewport.GotoTop()
+					return m, nil
 				} else if m.selectionMode == modeEdit {
 					m.state = stateEditTaskInput
-					m.textInput.SetValue(selected.Text)
+					m.textInput.SetValue(core.TextWithTags(selected))
 					m.textInput.Focus()
 					return m, nil
 				}
@@ -391,6 +1455,35 @@ func (m model) updateTaskSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// updateConfirmDone handles completing a task that still has open subtasks
+func (m model) updateConfirmDone(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		selected := m.selectList[m.cursor]
+		switch msg.String() {
+		case "a", "y":
+			var ids []string
+			for _, sub := range core.GetOpenSubtasks(m.entries, selected.ID) {
+				ids = append(ids, sub.ID)
+			}
+			m.msg = m.blockedWarning(selected)
+			m.completeTodos(append(ids, selected.ID)...)
+		case "p":
+			m.msg = m.blockedWarning(selected)
+			m.completeTodos(selected.ID)
+		case "esc", "n":
+			m.state = stateViewMain
+			m.updateViewport()
+			return m, nil
+		default:
+			return m, nil
+		}
+		m.save()
+		m.state = stateViewMain
+	}
+	return m, nil
+}
+
 func (m model) updateEditTask(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	switch msg := msg.(type) {
@@ -399,7 +1492,7 @@ func (m model) updateEditTask(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case tea.KeyEnter:
 			// Save edit
 			selected := m.selectList[m.cursor]
-			m.entries = core.EditEntry(m.entries, selected.ID, m.textInput.Value())
+			m.entries = core.EditEntry(m.entries, selected.ID, m.textInput.Value(), m.author)
 			m.save()
 			m.textInput.SetValue("")
 			m.state = stateViewMain
@@ -422,9 +1515,41 @@ func (m model) View() string {
 		return m.viewTaskSelect()
 	case stateEditTaskInput:
 		return fmt.Sprintf("\n%s\n\n%s\n\n(Esc to cancel)", cMagenta.Render("Edit Task:"), m.textInput.View())
+	case stateBoardView:
+		return m.viewBoard()
+	case stateConfirmDone:
+		selected := m.selectList[m.cursor]
+		return fmt.Sprintf("\n%s\n\n%s has %d open subtasks.\n\n%s",
+			cMagenta.Render("Mark as Done"),
+			cYellow.Render(selected.Text),
+			len(core.GetOpenSubtasks(m.entries, selected.ID)),
+			cGray.Render("a: complete all | p: only this task | Esc to cancel"))
 	case stateHistoryView:
+		title := "History (Completed Tasks)"
+		if m.histArchive {
+			title = "History (Completed Tasks, including the archive)"
+		}
 		return fmt.Sprintf("%s\n\n%s\n\n%s",
-			cMagenta.Render("History (Completed Tasks)"),
+			cMagenta.Render(title),
+			m.viewport.View(),
+			cGray.Render("Press any key to go back"))
+	case stateUpcomingView:
+		return fmt.Sprintf("%s\n\n%s\n\n%s",
+			cMagenta.Render("Upcoming (Open Tasks by Due Date)"),
+			m.viewport.View(),
+			cGray.Render("Press any key to go back"))
+	case stateMineView:
+		return fmt.Sprintf("%s\n\n%s\n\n%s",
+			cMagenta.Render("Assigned to "+m.author),
+			m.viewport.View(),
+			cGray.Render("Press any key to go back"))
+	case stateLogView:
+		title := "Timeline"
+		if e, ok := core.FindEntry(m.entries, m.logID); ok {
+			title += ": " + e.Text
+		}
+		return fmt.Sprintf("%s\n\n%s\n\n%s",
+			cMagenta.Render(title),
 			m.viewport.View(),
 			cGray.Render("Press any key to go back"))
 	default:
@@ -441,8 +1566,23 @@ func (m model) viewMain() string {
 	   | | |_| | | |__| | (_) |
 	   |_|\__,_|_|_____/ \___/ `)
 
-	header := fmt.Sprintf("%s\nAuthor: %s", title, cBlue.Render(m.author))
+	header := fmt.Sprintf("%s\nAuthor: %s", title, cBlue.Render(m.author)) + cGray.Render(" | File: "+displayPath(m.filePath))
+	if tabs := m.listTabs(); len(tabs) > 1 {
+		header += "\n" + m.renderTabBar(tabs)
+	}
+	if m.tagFilter != "" {
+		header += cGray.Render(" | Filter: ") + cBlue.Render("#"+m.tagFilter) + cGray.Render(" (/tag to clear)")
+	}
+	if m.query != "" && m.state != stateFind {
+		header += cGray.Render(" | Search: ") + cBlue.Render(m.query) + cGray.Render(" (Esc to clear)")
+	}
+	if m.corrupt {
+		header += "\n" + cRed.Render("Data file is corrupt! Type /recover to restore the last backup.")
+	}
 	help := cGray.Render(" Type to add note | /todo [text] | /help | /exit")
+	if m.state == stateFind {
+		help = cGray.Render(" Search: fuzzy words and filters like tag:api -done | Enter to keep results | Esc to clear")
+	}
 
 	if m.msg != "" {
 		help = cCyan.Render(m.msg) + "\n" + help
@@ -459,6 +1599,24 @@ func (m model) viewMain() string {
 	)
 }
 
+// renderTabBar shows the lists with the current one highlighted
+func (m model) renderTabBar(tabs []string) string {
+	var parts []string
+	for _, name := range tabs {
+		if name == m.list {
+			parts = append(parts, cMagenta.Bold(true).Render("[ "+name+" ]"))
+		} else {
+			parts = append(parts, cGray.Render("  "+name+"  "))
+		}
+	}
+	return strings.Join(parts, "") + cGray.Render("  (Tab to switch)")
+}
+
+// multiselect reports whether the selection mode acts on several entries marked with Space
+func (m model) multiselect() bool {
+	return m.selectionMode == modeRemove || m.selectionMode == modeTrash || m.selectionMode == modeMove
+}
+
 func (m model) viewTaskSelect() string {
 	title := "Select Item"
 	switch m.selectionMode {
@@ -470,6 +1628,43 @@ func (m model) viewTaskSelect() string {
 		title = "Remove Item"
 	case modeEdit:
 		title = "Edit Item"
+	case modeLog:
+		title = "Show Timeline"
+	case modeTrash:
+		title = "Trash"
+	case modeMove:
+		title = "Move To List: " + m.pendingList
+	case modePriority:
+		title = "Set Priority"
+		if m.pendingPrio != core.PriorityNone {
+			title += ": " + string(m.pendingPrio)
+		} else {
+			title = "Clear Priority"
+		}
+	case modeSubtask:
+		title = "Add Subtask To: " + m.pendingText
+	case modeFold:
+		title = "Collapse / Expand"
+	case modeBlock:
+		title = "Block Task"
+	case modeBlocker:
+		if blocked, ok := core.FindEntry(m.entries, m.pendingBlock); ok {
+			title = "Blocked By (" + blocked.Text + ")"
+		}
+	case modeUnblock:
+		title = "Clear Blockers"
+	case modeStatus:
+		title = "Move To: " + strings.ToUpper(m.pendingStatus)
+	case modeDue:
+		title = "Clear Due Date"
+		if m.pendingDue != nil {
+			title = "Set Due Date: " + m.pendingDue.Format("Mon 02-01-2006")
+		}
+	}
+
+	depth := make(map[string]int, len(m.selectList))
+	for _, item := range core.FlattenTree(m.selectList, nil) {
+		depth[item.Entry.ID] = item.Depth
 	}
 
 	ss := cMagenta.Render(title) + "\n\n"
@@ -480,7 +1675,7 @@ func (m model) viewTaskSelect() string {
 		}
 
 		selection := ""
-		if m.selectionMode == modeRemove {
+		if m.multiselect() {
 			if _, selected := m.selectedIDs[item.ID]; selected {
 				selection = "[x] "
 			} else {
@@ -488,7 +1683,15 @@ func (m model) viewTaskSelect() string {
 			}
 		}
 
-		line := fmt.Sprintf("%s %s%s %s", cursor, selection, item.Type, item.Text)
+		kind := string(item.Type)
+		if item.Type == core.TypeTodo {
+			kind = core.StatusOf(item, m.workflow)
+		}
+		line := fmt.Sprintf("%s %s%s%s %s%s %s%s%s", cursor, strings.Repeat("  ", depth[item.ID]), selection, m.shortIDs[item.ID], kind,
+			renderPriority(item.Priority), item.Text, renderTags(item.Tags), renderDue(item, time.Now()))
+		if item.Trashed() {
+			line += cGray.Render(" removed " + item.DeletedAt.Format("02-01-2006 15:04"))
+		}
 		if m.cursor == i {
 			ss += cYellow.Render(line) + "\n"
 		} else {
@@ -497,15 +1700,39 @@ func (m model) viewTaskSelect() string {
 	}
 
 	if m.selectionMode == modeRemove {
-		ss += "\n" + cGray.Render("Space to toggle | Enter to remove selected")
+		ss += "\n" + cGray.Render("Space to toggle | Enter to move selected to the trash")
+	} else if m.selectionMode == modeTrash {
+		ss += "\n" + cGray.Render("Space to toggle | Enter to restore selected | D to delete forever")
+	} else if m.selectionMode == modeMove {
+		ss += "\n" + cGray.Render("Space to toggle | Enter to move selected with their subtasks")
 	}
 
 	return ss
 }
 
 func main() {
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	fileFlag, args, err := parseFileFlag(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tuido: %v\n\n%s", err, cliUsage)
+		os.Exit(exitUsage)
+	}
+	if len(args) > 0 {
+		os.Exit(runCLI(args, fileFlag, os.Stdout, os.Stderr))
+	}
+
+	m := initialModel(fileFlag)
+	// Live reload is best effort; without a watcher the TUI still works
+	stop := func() {}
+	if changes, stopWatch, err := core.WatchFile(m.filePath); err == nil {
+		m.changes = changes
+		stop = stopWatch
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	_, err = p.Run()
+	// Stopped explicitly, as os.Exit skips deferred calls
+	stop()
+	if err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
 		os.Exit(1)
 	}